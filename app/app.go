@@ -45,11 +45,16 @@ type App struct {
 	ctx             context.Context
 	stop            context.CancelFunc // Signal App.run() to stop.
 	proxyCh         chan struct{}      // Waits for App.proxy() to return.
+	proxyErr        error              // Set if the proxy listener failed unexpectedly.
 	runCh           chan struct{}      // Waits for App.run() to return.
 	readyCh         chan struct{}      // Waits for startup tasks
 	voters          int
 	standbys        int
 	roles           RolesConfig
+	membership      func(old, new []client.NodeInfo)
+	members         []client.NodeInfo // Nodes seen at the last store refresh.
+	maxConnections  int               // Maximum number of connections handled by the proxy.
+	proxyProtocol   bool              // Whether connections may start with a PROXY header.
 }
 
 // New creates a new application node.
@@ -249,6 +254,9 @@ func New(dir string, options ...Option) (app *App, err error) {
 		voters:          o.Voters,
 		standbys:        o.StandBys,
 		roles:           RolesConfig{Voters: o.Voters, StandBys: o.StandBys},
+		membership:      o.MembershipFunc,
+		maxConnections:  o.MaxConnections,
+		proxyProtocol:   o.ProxyProtocol,
 	}
 
 	// Start the proxy if a TLS configuration was provided.
@@ -382,6 +390,10 @@ func (a *App) Handover(ctx context.Context) error {
 }
 
 // Close the application node, releasing all resources it created.
+//
+// If the TLS proxy listener stopped accepting connections unexpectedly before
+// Close was called, an error wrapping the listener failure is returned once
+// all resources have been released.
 func (a *App) Close() error {
 	// Stop the run goroutine.
 	a.stop()
@@ -394,6 +406,9 @@ func (a *App) Close() error {
 	if err := a.node.Close(); err != nil {
 		return err
 	}
+	if a.proxyErr != nil {
+		return fmt.Errorf("proxy stopped accepting connections: %w", a.proxyErr)
+	}
 	return nil
 }
 
@@ -468,9 +483,23 @@ func (a *App) Client(ctx context.Context) (*client.Client, error) {
 func (a *App) proxy() {
 	wg := sync.WaitGroup{}
 	ctx, cancel := context.WithCancel(a.ctx)
+
+	// Semaphore bounding the number of concurrent connections, if any.
+	var sem chan struct{}
+	if a.maxConnections > 0 {
+		sem = make(chan struct{}, a.maxConnections)
+	}
+
 	for {
 		client, err := a.listener.Accept()
 		if err != nil {
+			// If the app context is still active it means that
+			// Close() was not called and the listener failed for
+			// some other reason.
+			if a.ctx.Err() == nil {
+				a.error("proxy stopped accepting connections: %v", err)
+				a.proxyErr = err
+			}
 			cancel()
 			wg.Wait()
 			close(a.proxyCh)
@@ -478,15 +507,38 @@ func (a *App) proxy() {
 		}
 		address := client.RemoteAddr()
 		a.debug("new connection from %s", address)
+		if sem != nil {
+			select {
+			case sem <- struct{}{}:
+			default:
+				a.warn("reject connection from %s: too many connections", address)
+				client.Close()
+				continue
+			}
+		}
 		server, err := net.Dial("unix", a.nodeBindAddress)
 		if err != nil {
 			a.error("dial local node: %v", err)
 			client.Close()
+			if sem != nil {
+				<-sem
+			}
 			continue
 		}
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
+			if sem != nil {
+				defer func() { <-sem }()
+			}
+			if a.proxyProtocol {
+				if err := a.handleProxyHeader(client); err != nil {
+					a.warn("PROXY header from %s: %v", address, err)
+					client.Close()
+					server.Close()
+					return
+				}
+			}
 			if err := proxy(ctx, client, server, a.tls.Listen); err != nil {
 				a.error("proxy: %v", err)
 			}
@@ -494,6 +546,23 @@ func (a *App) proxy() {
 	}
 }
 
+// Consume the PROXY protocol header that the given connection starts with, if
+// any, logging the original client address.
+func (a *App) handleProxyHeader(conn net.Conn) error {
+	tcp, ok := conn.(*net.TCPConn)
+	if !ok {
+		return fmt.Errorf("connection is not a net.TCPConn")
+	}
+	addr, err := readProxyHeader(tcp)
+	if err != nil {
+		return err
+	}
+	if addr != nil {
+		a.debug("connection from %s proxied for %s", conn.RemoteAddr(), addr)
+	}
+	return nil
+}
+
 // Run background tasks. The join flag is true if the node is a brand new one
 // and should join the cluster.
 func (a *App) run(ctx context.Context, frequency time.Duration, join bool) {
@@ -543,6 +612,7 @@ func (a *App) run(ctx context.Context, frequency time.Duration, join bool) {
 				cli.Close()
 				continue
 			}
+			a.maybeNotifyMembership(servers)
 			a.store.Set(ctx, servers)
 
 			// If we are starting up, let's see if we should
@@ -643,6 +713,48 @@ again:
 	goto again
 }
 
+// Invoke the membership function if the given list of nodes differs from the
+// one seen at the previous refresh.
+//
+// The first refresh only records the current nodes: the seed entries that the
+// node store holds at startup lack IDs and roles, so comparing against them
+// would report spurious changes.
+func (a *App) maybeNotifyMembership(nodes []client.NodeInfo) {
+	old := a.members
+	a.members = nodes
+
+	if a.membership == nil || old == nil {
+		return
+	}
+
+	if sameNodes(old, nodes) {
+		return
+	}
+
+	a.membership(old, nodes)
+}
+
+// Return true if the two given lists contain the same nodes, regardless of
+// their order.
+func sameNodes(a, b []client.NodeInfo) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	nodes := make(map[client.NodeInfo]int, len(a))
+	for _, node := range a {
+		nodes[node]++
+	}
+	for _, node := range b {
+		if nodes[node] == 0 {
+			return false
+		}
+		nodes[node]--
+	}
+
+	return true
+}
+
 // Probe all given nodes for connectivity and metadata, then return a
 // RolesChanges object.
 func (a *App) makeRolesChanges(nodes []client.NodeInfo) RolesChanges {
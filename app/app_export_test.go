@@ -0,0 +1,13 @@
+package app
+
+import (
+	"net"
+)
+
+func (a *App) Listener() net.Listener {
+	return a.listener
+}
+
+func (a *App) ProxyDone() <-chan struct{} {
+	return a.proxyCh
+}
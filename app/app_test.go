@@ -8,6 +8,7 @@ import (
 	"database/sql"
 	"encoding/binary"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net"
 	"net/http"
@@ -21,6 +22,7 @@ import (
 	"github.com/canonical/go-dqlite"
 	"github.com/canonical/go-dqlite/app"
 	"github.com/canonical/go-dqlite/client"
+	"github.com/canonical/go-dqlite/logging"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -896,6 +898,105 @@ func TestOptions(t *testing.T) {
 	require.NotNil(t, app)
 }
 
+// The membership function is invoked when a new node joins the cluster.
+func TestMembershipFunc(t *testing.T) {
+	addr1 := "127.0.0.1:9001"
+	addr2 := "127.0.0.1:9002"
+
+	changes := make(chan []client.NodeInfo, 8)
+	membership := func(old, new []client.NodeInfo) {
+		changes <- new
+	}
+
+	app1, cleanup := newApp(t,
+		app.WithAddress(addr1),
+		app.WithMembershipFunc(membership),
+		app.WithRolesAdjustmentFrequency(100*time.Millisecond),
+	)
+	defer cleanup()
+
+	require.NoError(t, app1.Ready(context.Background()))
+
+	app2, cleanup := newApp(t, app.WithAddress(addr2), app.WithCluster([]string{addr1}))
+	defer cleanup()
+
+	require.NoError(t, app2.Ready(context.Background()))
+
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case nodes := <-changes:
+			if len(nodes) == 2 {
+				assert.Equal(t, addr1, nodes[0].Address)
+				assert.Equal(t, addr2, nodes[1].Address)
+				return
+			}
+		case <-timeout:
+			t.Fatal("membership change not notified")
+		}
+	}
+}
+
+// No membership change is notified while the cluster is stable.
+func TestMembershipFunc_Stable(t *testing.T) {
+	changes := make(chan []client.NodeInfo, 8)
+	membership := func(old, new []client.NodeInfo) {
+		changes <- new
+	}
+
+	app1, cleanup := newApp(t,
+		app.WithAddress("127.0.0.1:9001"),
+		app.WithMembershipFunc(membership),
+		app.WithRolesAdjustmentFrequency(100*time.Millisecond),
+	)
+	defer cleanup()
+
+	require.NoError(t, app1.Ready(context.Background()))
+
+	select {
+	case nodes := <-changes:
+		t.Fatalf("unexpected membership change: %v", nodes)
+	case <-time.After(500 * time.Millisecond):
+	}
+}
+
+// If the proxy listener fails while the app is running, Close reports it.
+func TestClose_ProxyError(t *testing.T) {
+	dir, cleanup := newDir(t)
+	defer cleanup()
+
+	cert, pool := loadCert(t)
+	app, err := app.New(dir,
+		app.WithAddress("127.0.0.1:9001"),
+		app.WithLogFunc(logging.Test(t)),
+		app.WithTLS(app.SimpleTLSConfig(cert, pool)),
+	)
+	require.NoError(t, err)
+
+	require.NoError(t, app.Listener().Close())
+	<-app.ProxyDone()
+
+	err = app.Close()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "proxy stopped accepting connections")
+}
+
+// The listener failure triggered by Close itself is not reported.
+func TestClose_NoProxyError(t *testing.T) {
+	dir, cleanup := newDir(t)
+	defer cleanup()
+
+	cert, pool := loadCert(t)
+	app, err := app.New(dir,
+		app.WithAddress("127.0.0.1:9001"),
+		app.WithLogFunc(logging.Test(t)),
+		app.WithTLS(app.SimpleTLSConfig(cert, pool)),
+	)
+	require.NoError(t, err)
+
+	assert.NoError(t, app.Close())
+}
+
 // Test client connections dropping uncleanly.
 func TestProxy_Error(t *testing.T) {
 	cert, pool := loadCert(t)
@@ -936,6 +1037,27 @@ func TestProxy_Error(t *testing.T) {
 	time.Sleep(250 * time.Millisecond)
 }
 
+// Connections beyond the configured limit are closed right away.
+func TestProxy_MaxConnections(t *testing.T) {
+	_, cleanup := newApp(t, app.WithAddress("127.0.0.1:9000"), app.WithMaxConnections(1))
+	defer cleanup()
+
+	// This connection stays in the TLS handshake, taking the only slot.
+	conn1, err := net.Dial("tcp", "127.0.0.1:9000")
+	require.NoError(t, err)
+	defer conn1.Close()
+
+	time.Sleep(100 * time.Millisecond)
+
+	conn2, err := net.Dial("tcp", "127.0.0.1:9000")
+	require.NoError(t, err)
+	defer conn2.Close()
+
+	conn2.SetReadDeadline(time.Now().Add(time.Second))
+	_, err = conn2.Read(make([]byte, 1))
+	assert.Equal(t, io.EOF, err)
+}
+
 // If the given context is cancelled before initial tasks are completed, an
 // error is returned.
 func TestReady_Cancel(t *testing.T) {
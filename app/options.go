@@ -65,6 +65,11 @@ func WithExternalConn(dialFunc client.DialFunc, acceptCh chan net.Conn) Option {
 //
 // The "dial" parameter must hold the TLS configuration to use when
 // establishing outgoing connections to other application nodes.
+//
+// Certificates can be rotated without restarting the node by setting the
+// GetCertificate callback of "listen" and the GetClientCertificate callback of
+// "dial": they are consulted at every handshake, so new connections pick up
+// the updated certificate while existing ones are left untouched.
 func WithTLS(listen *tls.Config, dial *tls.Config) Option {
 	return func(options *options) {
 		options.TLS = &tlsSetup{
@@ -139,6 +144,51 @@ func WithRolesAdjustmentFrequency(frequency time.Duration) Option {
 	}
 }
 
+// WithMaxConnections sets the maximum number of connections that the TLS proxy
+// (see WithTLS) handles concurrently. Connections accepted while the limit is
+// reached are closed right away, and clients will retry with another node or
+// later.
+//
+// If not used, or set to zero, there's no limit. Without TLS, connections are
+// handled directly by the dqlite engine and this option has no effect.
+func WithMaxConnections(n int) Option {
+	return func(options *options) {
+		options.MaxConnections = n
+	}
+}
+
+// WithProxyProtocol makes the TLS proxy (see WithTLS) accept connections that
+// start with a PROXY protocol v1 or v2 header, as sent by load balancers like
+// HAProxy or AWS ELB, and log the original client address that it carries.
+//
+// Connections without a header are handled as usual. Without TLS, connections
+// are handled directly by the dqlite engine and this option has no effect.
+func WithProxyProtocol() Option {
+	return func(options *options) {
+		options.ProxyProtocol = true
+	}
+}
+
+// WithMembershipFunc sets a function that will be invoked whenever this
+// application node notices that the cluster membership has changed, for
+// example because a node joined the cluster, was removed from it or changed
+// role.
+//
+// The old parameter holds the nodes that were previously known to this
+// application node and the new parameter the current ones.
+//
+// Changes are detected when the application node refreshes its node store
+// with the list of nodes fetched from the current leader, which happens at
+// the frequency set by WithRolesAdjustmentFrequency. The first refresh after
+// startup only records the current nodes, so changes that happened while the
+// application node was down are not reported. The function is invoked from
+// the application's background goroutine, so it should not block.
+func WithMembershipFunc(f func(old, new []client.NodeInfo)) Option {
+	return func(options *options) {
+		options.MembershipFunc = f
+	}
+}
+
 // WithLogFunc sets a custom log function.
 func WithLogFunc(log client.LogFunc) Option {
 	return func(options *options) {
@@ -200,6 +250,9 @@ type options struct {
 	Voters                   int
 	StandBys                 int
 	RolesAdjustmentFrequency time.Duration
+	MembershipFunc           func(old, new []client.NodeInfo)
+	MaxConnections           int
+	ProxyProtocol            bool
 	FailureDomain            uint64
 	NetworkLatency           time.Duration
 	UnixSocket               string
@@ -0,0 +1,153 @@
+package app
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// Signature of PROXY protocol v2 headers.
+var proxyV2Signature = []byte("\r\n\r\n\x00\r\nQUIT\n")
+
+// Maximum length of a PROXY protocol v1 header, including the final CRLF.
+const proxyV1MaxLen = 107
+
+// How long to wait for a PROXY protocol header.
+const proxyHeaderTimeout = 5 * time.Second
+
+// Read a PROXY protocol v1 or v2 header from the given connection, if present,
+// and return the client address it carries.
+//
+// The header is consumed exactly, without buffering any data that follows it,
+// so the connection can be passed on unchanged. If the connection doesn't
+// start with a header, nothing is consumed and a nil address is returned. The
+// address is nil also for headers that don't carry one (e.g. v2 LOCAL ones).
+func readProxyHeader(conn *net.TCPConn) (net.Addr, error) {
+	conn.SetReadDeadline(time.Now().Add(proxyHeaderTimeout))
+	defer conn.SetReadDeadline(time.Time{})
+
+	b, err := peekByte(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	switch b {
+	case 'P':
+		return readProxyHeaderV1(conn)
+	case proxyV2Signature[0]:
+		return readProxyHeaderV2(conn)
+	default:
+		return nil, nil
+	}
+}
+
+// Return the next byte available on the given connection, without consuming
+// it.
+func peekByte(conn *net.TCPConn) (byte, error) {
+	raw, err := conn.SyscallConn()
+	if err != nil {
+		return 0, err
+	}
+
+	buf := make([]byte, 1)
+	n := 0
+	var rerr error
+	err = raw.Read(func(fd uintptr) bool {
+		n, _, rerr = unix.Recvfrom(int(fd), buf, unix.MSG_PEEK)
+		return rerr != unix.EAGAIN
+	})
+	if err != nil {
+		return 0, err
+	}
+	if rerr != nil {
+		return 0, rerr
+	}
+	if n == 0 {
+		return 0, io.EOF
+	}
+
+	return buf[0], nil
+}
+
+// Read a v1 header, in the form "PROXY TCP4 <src> <dst> <sport> <dport>\r\n".
+func readProxyHeaderV1(conn *net.TCPConn) (net.Addr, error) {
+	line := make([]byte, 0, proxyV1MaxLen)
+	b := make([]byte, 1)
+	for !bytes.HasSuffix(line, []byte("\r\n")) {
+		if len(line) == proxyV1MaxLen {
+			return nil, fmt.Errorf("PROXY v1 header too long")
+		}
+		if _, err := io.ReadFull(conn, b); err != nil {
+			return nil, err
+		}
+		line = append(line, b[0])
+	}
+
+	fields := strings.Fields(string(line))
+	if len(fields) < 2 || fields[0] != "PROXY" {
+		return nil, fmt.Errorf("invalid PROXY v1 header")
+	}
+	if fields[1] == "UNKNOWN" {
+		return nil, nil
+	}
+	if len(fields) != 6 || (fields[1] != "TCP4" && fields[1] != "TCP6") {
+		return nil, fmt.Errorf("invalid PROXY v1 header")
+	}
+
+	ip := net.ParseIP(fields[2])
+	port, err := strconv.ParseUint(fields[4], 10, 16)
+	if ip == nil || err != nil {
+		return nil, fmt.Errorf("invalid PROXY v1 source address")
+	}
+
+	return &net.TCPAddr{IP: ip, Port: int(port)}, nil
+}
+
+// Read a v2 header, made of a 16-byte prefix followed by the number of bytes
+// of address information that the prefix declares.
+func readProxyHeaderV2(conn *net.TCPConn) (net.Addr, error) {
+	prefix := make([]byte, 16)
+	if _, err := io.ReadFull(conn, prefix); err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(prefix[:12], proxyV2Signature) {
+		return nil, fmt.Errorf("invalid PROXY v2 signature")
+	}
+	if prefix[12]>>4 != 2 {
+		return nil, fmt.Errorf("unsupported PROXY version %d", prefix[12]>>4)
+	}
+
+	info := make([]byte, binary.BigEndian.Uint16(prefix[14:]))
+	if _, err := io.ReadFull(conn, info); err != nil {
+		return nil, err
+	}
+
+	// LOCAL command, e.g. health checks from the load balancer itself.
+	if prefix[12]&0x0f == 0 {
+		return nil, nil
+	}
+
+	switch prefix[13] {
+	case 0x11: // TCP over IPv4
+		if len(info) < 12 {
+			return nil, fmt.Errorf("short PROXY v2 IPv4 address")
+		}
+		port := binary.BigEndian.Uint16(info[8:])
+		return &net.TCPAddr{IP: net.IP(info[0:4]), Port: int(port)}, nil
+	case 0x21: // TCP over IPv6
+		if len(info) < 36 {
+			return nil, fmt.Errorf("short PROXY v2 IPv6 address")
+		}
+		port := binary.BigEndian.Uint16(info[32:])
+		return &net.TCPAddr{IP: net.IP(info[0:16]), Port: int(port)}, nil
+	default:
+		return nil, nil
+	}
+}
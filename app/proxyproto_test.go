@@ -0,0 +1,101 @@
+package app
+
+import (
+	"encoding/binary"
+	"io/ioutil"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadProxyHeader(t *testing.T) {
+	v2 := func(command, family byte, info []byte) []byte {
+		header := append([]byte{}, proxyV2Signature...)
+		header = append(header, 0x20|command, family, 0, 0)
+		binary.BigEndian.PutUint16(header[14:], uint16(len(info)))
+		return append(header, info...)
+	}
+	ipv4 := []byte{10, 0, 0, 1, 10, 0, 0, 2, 0x30, 0x39, 0x23, 0x28}
+
+	cases := []struct {
+		title  string
+		header []byte
+		addr   string
+	}{
+		{"none", nil, ""},
+		{"v1 tcp4", []byte("PROXY TCP4 10.0.0.1 10.0.0.2 12345 9000\r\n"), "10.0.0.1:12345"},
+		{"v1 tcp6", []byte("PROXY TCP6 ::1 ::1 12345 9000\r\n"), "[::1]:12345"},
+		{"v1 unknown", []byte("PROXY UNKNOWN\r\n"), ""},
+		{"v2 proxy", v2(1, 0x11, ipv4), "10.0.0.1:12345"},
+		{"v2 local", v2(0, 0x00, nil), ""},
+	}
+
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			addr, data, err := readProxyHeaderFrom(t, append(c.header, []byte("hello")...))
+			require.NoError(t, err)
+			if c.addr == "" {
+				assert.Nil(t, addr)
+			} else {
+				assert.Equal(t, c.addr, addr.String())
+			}
+
+			// The data following the header is left untouched.
+			assert.Equal(t, "hello", data)
+		})
+	}
+}
+
+func TestReadProxyHeader_Invalid(t *testing.T) {
+	cases := []struct {
+		title  string
+		header []byte
+		err    string
+	}{
+		{"v1 garbage", []byte("PROXY\r\n"), "invalid PROXY v1 header"},
+		{"v1 bad address", []byte("PROXY TCP4 foo 10.0.0.2 12345 9000\r\n"), "invalid PROXY v1 source address"},
+		{"v2 bad signature", []byte("\r\n\r\nfoo bar baz!"), "invalid PROXY v2 signature"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			_, _, err := readProxyHeaderFrom(t, c.header)
+			assert.EqualError(t, err, c.err)
+		})
+	}
+}
+
+// Send the given data over a TCP connection and read a PROXY header from the
+// other end, returning the remaining data.
+func readProxyHeaderFrom(t *testing.T, data []byte) (net.Addr, string, error) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	go func() {
+		conn, err := net.Dial("tcp", listener.Addr().String())
+		if err != nil {
+			return
+		}
+		conn.Write(data)
+		conn.Close()
+	}()
+
+	conn, err := listener.Accept()
+	require.NoError(t, err)
+	defer conn.Close()
+
+	addr, err := readProxyHeader(conn.(*net.TCPConn))
+	if err != nil {
+		return nil, "", err
+	}
+
+	rest, err := ioutil.ReadAll(conn)
+	require.NoError(t, err)
+
+	return addr, string(rest), nil
+}
@@ -0,0 +1,120 @@
+package client
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/google/renameio"
+	"github.com/pkg/errors"
+)
+
+// BackupStore is used by Client.BackupTo to save backups, for example in a
+// local directory (see DirBackupStore) or in an object storage service.
+//
+// An implementation for S3-compatible storage typically maps Put to a
+// PutObject call and Get to a GetObject call on a bucket, using the key as
+// object name.
+type BackupStore interface {
+	// Put saves the content read from r under the given key, replacing
+	// any previous content.
+	Put(ctx context.Context, key string, r io.Reader) error
+
+	// Get returns the content saved under the given key.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+}
+
+// BackupTo dumps the database with the given name and saves it in the given
+// store, using the database name as key and the archive format of WriteDump.
+//
+// The archive is streamed to the store while it's being encoded, so it's
+// never held in memory on top of the dumped files.
+//
+// A backup can be read back with ReadBackup.
+func (c *Client) BackupTo(ctx context.Context, dbname string, store BackupStore) error {
+	files, err := c.Dump(ctx, dbname)
+	if err != nil {
+		return err
+	}
+	if err := putDump(ctx, store, dbname, files); err != nil {
+		return errors.Wrapf(err, "save backup of %s", dbname)
+	}
+	return nil
+}
+
+// Save the archive of the given files in the store under the given key,
+// encoding it in a separate goroutine while the store reads it.
+func putDump(ctx context.Context, store BackupStore, key string, files []File) error {
+	r, w := io.Pipe()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		w.CloseWithError(WriteDump(w, files))
+	}()
+
+	err := store.Put(ctx, key, r)
+
+	// Unblock the writer in case the store stopped reading early.
+	r.Close()
+	<-done
+
+	return err
+}
+
+// ReadBackup reads back the files of the backup of the database with the given
+// name that BackupTo saved in the given store.
+//
+// The files hold the main database file and its WAL, as returned by Dump: once
+// written to a local directory they can be opened with plain SQLite, for
+// example to inspect the backup or to seed the database of a new cluster.
+func ReadBackup(ctx context.Context, dbname string, store BackupStore) ([]File, error) {
+	r, err := store.Get(ctx, dbname)
+	if err != nil {
+		return nil, errors.Wrapf(err, "get backup of %s", dbname)
+	}
+	defer r.Close()
+
+	files, err := ReadDump(r)
+	if err != nil {
+		return nil, errors.Wrapf(err, "read backup of %s", dbname)
+	}
+	return files, nil
+}
+
+// DirBackupStore is a BackupStore which saves backups as files in a local
+// directory.
+type DirBackupStore struct {
+	dir string
+}
+
+// NewDirBackupStore creates a DirBackupStore saving backups in the given
+// directory, which must exist.
+func NewDirBackupStore(dir string) *DirBackupStore {
+	return &DirBackupStore{dir: dir}
+}
+
+// Put atomically writes the content read from r to the file named after the
+// given key.
+func (s *DirBackupStore) Put(ctx context.Context, key string, r io.Reader) error {
+	file, err := renameio.TempFile(s.dir, s.path(key))
+	if err != nil {
+		return err
+	}
+	defer file.Cleanup()
+
+	if _, err := io.Copy(file, r); err != nil {
+		return err
+	}
+
+	return file.CloseAtomicallyReplace()
+}
+
+// Get opens the file named after the given key.
+func (s *DirBackupStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	return os.Open(s.path(key))
+}
+
+func (s *DirBackupStore) path(key string) string {
+	return filepath.Join(s.dir, filepath.Base(key))
+}
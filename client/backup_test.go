@@ -0,0 +1,132 @@
+package client_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/canonical/go-dqlite/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDirBackupStore(t *testing.T) {
+	dir, cleanup := newDir(t)
+	defer cleanup()
+
+	store := client.NewDirBackupStore(dir)
+	ctx := context.Background()
+
+	require.NoError(t, store.Put(ctx, "test.db", strings.NewReader("hello")))
+	require.NoError(t, store.Put(ctx, "test.db", strings.NewReader("world")))
+
+	r, err := store.Get(ctx, "test.db")
+	require.NoError(t, err)
+	defer r.Close()
+
+	data, err := ioutil.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, "world", string(data))
+}
+
+// A backup can be read back and opened with plain SQLite.
+func TestClient_BackupTo(t *testing.T) {
+	node, cleanup := newNode(t)
+	defer cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	cli, err := client.New(ctx, node.BindAddress())
+	require.NoError(t, err)
+	defer cli.Close()
+
+	require.NoError(t, cli.CreateDatabase(ctx, "test.db"))
+	require.NoError(t, cli.SetUserVersion(ctx, "test.db", 7))
+
+	dir, cleanup := newDir(t)
+	defer cleanup()
+
+	store := client.NewDirBackupStore(dir)
+	require.NoError(t, cli.BackupTo(ctx, "test.db", store))
+
+	files, err := client.ReadBackup(ctx, "test.db", store)
+	require.NoError(t, err)
+
+	restore, cleanup := newDir(t)
+	defer cleanup()
+
+	for _, file := range files {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(restore, file.Name), file.Data, 0600))
+	}
+
+	db, err := sql.Open("sqlite3", filepath.Join(restore, "test.db"))
+	require.NoError(t, err)
+	defer db.Close()
+
+	var version int
+	require.NoError(t, db.QueryRow("PRAGMA user_version").Scan(&version))
+	assert.Equal(t, 7, version)
+}
+
+func TestReadBackup(t *testing.T) {
+	dir, cleanup := newDir(t)
+	defer cleanup()
+
+	store := client.NewDirBackupStore(dir)
+	ctx := context.Background()
+
+	files := []client.File{{Name: "test.db", Data: []byte("main")}}
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, client.WriteDump(buf, files))
+	require.NoError(t, store.Put(ctx, "test.db", buf))
+
+	backup, err := client.ReadBackup(ctx, "test.db", store)
+	require.NoError(t, err)
+	assert.Equal(t, files, backup)
+
+	_, err = client.ReadBackup(ctx, "other.db", store)
+	assert.Error(t, err)
+}
+
+// The archive is streamed to the store, and a store failing before reading all
+// of it doesn't block the encoder.
+func TestPutDump(t *testing.T) {
+	dir, cleanup := newDir(t)
+	defer cleanup()
+
+	store := client.NewDirBackupStore(dir)
+	ctx := context.Background()
+
+	files := []client.File{
+		{Name: "test.db", Data: bytes.Repeat([]byte("x"), 1<<20)},
+		{Name: "test.db-wal", Data: []byte("wal")},
+	}
+	require.NoError(t, client.PutDump(ctx, store, "test.db", files))
+
+	backup, err := client.ReadBackup(ctx, "test.db", store)
+	require.NoError(t, err)
+	assert.Equal(t, files, backup)
+
+	err = client.PutDump(ctx, failingBackupStore{}, "test.db", files)
+	assert.EqualError(t, err, "boom")
+}
+
+// Backup store which fails after reading the first byte.
+type failingBackupStore struct {
+	client.BackupStore
+}
+
+func (failingBackupStore) Put(ctx context.Context, key string, r io.Reader) error {
+	if _, err := r.Read(make([]byte, 1)); err != nil {
+		return err
+	}
+	return fmt.Errorf("boom")
+}
@@ -2,6 +2,9 @@ package client
 
 import (
 	"context"
+	"database/sql/driver"
+	"fmt"
+	"time"
 
 	"github.com/canonical/go-dqlite/internal/protocol"
 	"github.com/pkg/errors"
@@ -11,16 +14,35 @@ import (
 type DialFunc = protocol.DialFunc
 
 // Client speaks the dqlite wire protocol.
+//
+// Since dqlite supports only one database per connection, the methods taking
+// a database name (e.g. CreateDatabase and UserVersion) must always be given
+// the same name on a given client: use a separate client for each database.
 type Client struct {
 	protocol *protocol.Protocol
+	db       *database // Database opened on this connection, if any.
+}
+
+// Name and ID of a database opened on the connection.
+type database struct {
+	name string
+	id   uint32
 }
 
 // Option that can be used to tweak client parameters.
 type Option func(*options)
 
 type options struct {
-	DialFunc DialFunc
-	LogFunc  LogFunc
+	DialFunc       DialFunc
+	LogFunc        LogFunc
+	FailureFunc    func([]ConnectAttempt)
+	AttemptTimeout time.Duration
+	BackoffFactor  time.Duration
+	BackoffCap     time.Duration
+	RetryLimit     uint
+	Parallel       int
+	CallTimeout    time.Duration
+	Tracer         Tracer
 }
 
 // WithDialFunc sets a custom dial function for creating the client network
@@ -39,6 +61,100 @@ func WithLogFunc(log LogFunc) Option {
 	}
 }
 
+// WithAttemptTimeout sets the timeout that FindLeader applies to each
+// individual attempt to probe a node for leadership.
+//
+// If not used, the default is 15 seconds.
+func WithAttemptTimeout(timeout time.Duration) Option {
+	return func(options *options) {
+		options.AttemptTimeout = timeout
+	}
+}
+
+// WithConnectionBackoffFactor sets the exponential backoff factor that
+// FindLeader uses between rounds of attempts.
+//
+// If not used, the default is 100 milliseconds.
+func WithConnectionBackoffFactor(factor time.Duration) Option {
+	return func(options *options) {
+		options.BackoffFactor = factor
+	}
+}
+
+// WithConnectionBackoffCap sets the maximum backoff that FindLeader waits
+// between rounds of attempts, regardless of the backoff factor.
+//
+// If not used, the default is 1 second.
+func WithConnectionBackoffCap(cap time.Duration) Option {
+	return func(options *options) {
+		options.BackoffCap = cap
+	}
+}
+
+// WithRetryLimit sets the maximum number of times FindLeader retries to find
+// the leader before giving up.
+//
+// If not used, the default is 0 (unlimited retries).
+func WithRetryLimit(limit uint) Option {
+	return func(options *options) {
+		options.RetryLimit = limit
+	}
+}
+
+// WithParallelAttempts sets how many nodes FindLeader probes concurrently. The
+// first leader connection that is established is used, and the other attempts
+// are cancelled.
+//
+// If not used, the default is to probe one node at a time.
+func WithParallelAttempts(n int) Option {
+	return func(options *options) {
+		options.Parallel = n
+	}
+}
+
+// WithCallTimeout sets a timeout for each individual request sent by the
+// client, so a hung node can't block a call forever even if the given context
+// has no deadline.
+//
+// When a call times out, the returned error has a net.Error cause whose
+// Timeout method returns true, and the client must be closed.
+//
+// If not used, the default is no timeout.
+func WithCallTimeout(timeout time.Duration) Option {
+	return func(options *options) {
+		options.CallTimeout = timeout
+	}
+}
+
+// Tracer can be used to trace connection attempts and requests, see
+// WithTracer.
+type Tracer = protocol.Tracer
+
+// WithTracer sets a tracer that gets a span for each attempt made by
+// FindLeader to find the leader, including the redirects to the leader
+// reported by other nodes, and for each request sent by the client.
+func WithTracer(tracer Tracer) Option {
+	return func(options *options) {
+		options.Tracer = tracer
+	}
+}
+
+// ConnectAttempt holds information about a failed attempt to reach the leader
+// through a single node.
+type ConnectAttempt = protocol.ConnectAttempt
+
+// WithConnectorFailureFunc sets a function that FindLeader invokes when it
+// gives up trying to find the leader, either because the retry limit was
+// reached or because the context is done.
+//
+// The function receives all the failed attempts that were made, in order, so
+// it can be used to report details about the failure.
+func WithConnectorFailureFunc(f func(attempts []ConnectAttempt)) Option {
+	return func(options *options) {
+		options.FailureFunc = f
+	}
+}
+
 // New creates a new client connected to the dqlite node with the given
 // address.
 func New(ctx context.Context, address string, options ...Option) (*Client, error) {
@@ -58,6 +174,8 @@ func New(ctx context.Context, address string, options ...Option) (*Client, error
 		conn.Close()
 		return nil, err
 	}
+	protocol.SetCallTimeout(o.CallTimeout)
+	protocol.SetTracer(o.Tracer)
 
 	client := &Client{protocol: protocol}
 
@@ -108,6 +226,23 @@ func (c *Client) Cluster(ctx context.Context) ([]NodeInfo, error) {
 	return servers, nil
 }
 
+// Voters returns information about the voting nodes in the cluster.
+func (c *Client) Voters(ctx context.Context) ([]NodeInfo, error) {
+	servers, err := c.Cluster(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	voters := make([]NodeInfo, 0, len(servers))
+	for _, server := range servers {
+		if server.Role == Voter {
+			voters = append(voters, server)
+		}
+	}
+
+	return voters, nil
+}
+
 // File holds the content of a single database file.
 type File struct {
 	Name string
@@ -188,8 +323,8 @@ func (c *Client) Add(ctx context.Context, node NodeInfo) error {
 // - StandBy: the node will replicate data but won't participate in quorum.
 // - Spare: the node won't replicate data and won't participate in quorum.
 //
-// If the target node does not exist or has already the desired role, an error
-// is returned.
+// If the target node has already the desired role, an error is returned. If it
+// does not exist, the error matches ErrNodeNotFound.
 func (c *Client) Assign(ctx context.Context, id uint64, role NodeRole) error {
 	request := protocol.Message{}
 	response := protocol.Message{}
@@ -204,7 +339,7 @@ func (c *Client) Assign(ctx context.Context, id uint64, role NodeRole) error {
 	}
 
 	if err := protocol.DecodeEmpty(&response); err != nil {
-		return err
+		return c.nodeError(ctx, id, err)
 	}
 
 	return nil
@@ -212,7 +347,14 @@ func (c *Client) Assign(ctx context.Context, id uint64, role NodeRole) error {
 
 // Transfer leadership from the current leader to another node.
 //
-// This must be invoked one client connected to the current leader.
+// If id is zero, raft picks the most up-to-date voter as the target.
+//
+// This must be invoked one client connected to the current leader. It's
+// typically used right before shutting down the leader, to avoid waiting for
+// an election timeout before writes can be served again.
+//
+// If the target node does not exist, an error matching ErrNodeNotFound is
+// returned.
 func (c *Client) Transfer(ctx context.Context, id uint64) error {
 	request := protocol.Message{}
 	response := protocol.Message{}
@@ -227,13 +369,16 @@ func (c *Client) Transfer(ctx context.Context, id uint64) error {
 	}
 
 	if err := protocol.DecodeEmpty(&response); err != nil {
-		return err
+		return c.nodeError(ctx, id, err)
 	}
 
 	return nil
 }
 
 // Remove a node from the cluster.
+//
+// If the target node does not exist, an error matching ErrNodeNotFound is
+// returned.
 func (c *Client) Remove(ctx context.Context, id uint64) error {
 	request := protocol.Message{}
 	request.Init(4096)
@@ -247,7 +392,7 @@ func (c *Client) Remove(ctx context.Context, id uint64) error {
 	}
 
 	if err := protocol.DecodeEmpty(&response); err != nil {
-		return err
+		return c.nodeError(ctx, id, err)
 	}
 
 	return nil
@@ -305,6 +450,227 @@ func (c *Client) Weight(ctx context.Context, weight uint64) error {
 	return nil
 }
 
+// CreateDatabase creates a new database with the given name.
+//
+// The client must be connected to the current leader (see FindLeader). The
+// database is created by writing its header page, so its creation is
+// replicated to the rest of the cluster like any other write, and it can be
+// configured (e.g. with pragmas) before the application starts using it.
+//
+// If a database with the given name already exists, an error is returned.
+// When several clients try to create the same database at the same time, more
+// than one may succeed, but the database is never reset once it has been
+// configured.
+func (c *Client) CreateDatabase(ctx context.Context, name string) (err error) {
+	db, err := c.open(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	// This must be checked before starting the write transaction, since
+	// SQLite sets up the first page of an empty database at that point.
+	pages, err := c.queryInt64(ctx, db, "PRAGMA page_count")
+	if err != nil {
+		return err
+	}
+	if pages > 0 {
+		return fmt.Errorf("database %q already exists", name)
+	}
+
+	if err := c.exec(ctx, db, "BEGIN IMMEDIATE"); err != nil {
+		return errors.Wrapf(err, "failed to create database %q", name)
+	}
+	defer func() {
+		if err != nil {
+			c.exec(ctx, db, "ROLLBACK")
+		}
+	}()
+
+	// If another client created the database in the meantime, make sure
+	// we don't reset a version it has already set.
+	version, err := c.queryInt64(ctx, db, "PRAGMA user_version")
+	if err != nil {
+		return err
+	}
+	if version != 0 {
+		return fmt.Errorf("database %q already exists", name)
+	}
+
+	if err := c.exec(ctx, db, "PRAGMA user_version = 0"); err != nil {
+		return errors.Wrapf(err, "failed to create database %q", name)
+	}
+
+	if err := c.exec(ctx, db, "COMMIT"); err != nil {
+		return errors.Wrapf(err, "failed to create database %q", name)
+	}
+
+	return nil
+}
+
+// UserVersion returns the value of the user_version pragma of the database
+// with the given name.
+//
+// The client must be connected to the current leader (see FindLeader).
+func (c *Client) UserVersion(ctx context.Context, name string) (int, error) {
+	db, err := c.open(ctx, name)
+	if err != nil {
+		return 0, err
+	}
+
+	version, err := c.queryInt64(ctx, db, "PRAGMA user_version")
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to get user version of database %q", name)
+	}
+
+	return int(version), nil
+}
+
+// SetUserVersion sets the value of the user_version pragma of the database
+// with the given name.
+//
+// The client must be connected to the current leader (see FindLeader). The
+// change is replicated to the rest of the cluster like any other write.
+func (c *Client) SetUserVersion(ctx context.Context, name string, version int) error {
+	db, err := c.open(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	sql := fmt.Sprintf("PRAGMA user_version = %d", version)
+	if err := c.exec(ctx, db, sql); err != nil {
+		return errors.Wrapf(err, "failed to set user version of database %q", name)
+	}
+
+	return nil
+}
+
+// Return an error matching ErrNodeNotFound if the request for the node with
+// the given ID failed because the cluster has no such node.
+//
+// The failure codes for unknown IDs differ across raft versions, so the
+// cluster configuration is checked instead.
+func (c *Client) nodeError(ctx context.Context, id uint64, err error) error {
+	if _, ok := errors.Cause(err).(protocol.ErrRequest); !ok || id == 0 {
+		return err
+	}
+
+	nodes, cerr := c.Cluster(ctx)
+	if cerr != nil {
+		return err
+	}
+	for _, node := range nodes {
+		if node.ID == id {
+			return err
+		}
+	}
+
+	return nodeNotFoundError{err}
+}
+
+// Wraps a request failure caused by an unknown node ID.
+type nodeNotFoundError struct {
+	error
+}
+
+func (e nodeNotFoundError) Unwrap() error {
+	return e.error
+}
+
+func (e nodeNotFoundError) Is(target error) bool {
+	return target == ErrNodeNotFound
+}
+
+// Open the database with the given name on the node we're connected with and
+// return its ID.
+//
+// Since dqlite supports only one database per connection, an error is
+// returned if a database with a different name was already opened.
+func (c *Client) open(ctx context.Context, name string) (uint32, error) {
+	if c.db != nil {
+		if c.db.name != name {
+			return 0, fmt.Errorf("client has already opened database %q", c.db.name)
+		}
+		return c.db.id, nil
+	}
+
+	request := protocol.Message{}
+	request.Init(4096)
+	response := protocol.Message{}
+	response.Init(4096)
+
+	protocol.EncodeOpen(&request, name, 0, "volatile")
+
+	if err := c.protocol.Call(ctx, &request, &response); err != nil {
+		return 0, errors.Wrap(err, "failed to send open request")
+	}
+
+	id, err := protocol.DecodeDb(&response)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to open database %q", name)
+	}
+
+	c.db = &database{name: name, id: id}
+
+	return id, nil
+}
+
+// Execute the given SQL statement against the database with the given ID.
+func (c *Client) exec(ctx context.Context, db uint32, sql string, args ...driver.NamedValue) error {
+	request := protocol.Message{}
+	request.Init(4096)
+	response := protocol.Message{}
+	response.Init(4096)
+
+	protocol.EncodeExecSQL(&request, uint64(db), sql, args)
+
+	if err := c.protocol.Call(ctx, &request, &response); err != nil {
+		return errors.Wrap(err, "failed to send exec request")
+	}
+
+	if _, err := protocol.DecodeResult(&response); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Run the given SQL query against the database with the given ID, and return
+// the value of the first column of the first row, which must be an integer.
+func (c *Client) queryInt64(ctx context.Context, db uint32, sql string) (int64, error) {
+	request := protocol.Message{}
+	request.Init(4096)
+	response := protocol.Message{}
+	response.Init(4096)
+
+	protocol.EncodeQuerySQL(&request, uint64(db), sql, nil)
+
+	if err := c.protocol.Call(ctx, &request, &response); err != nil {
+		return 0, errors.Wrap(err, "failed to send query request")
+	}
+
+	rows, err := protocol.DecodeRows(&response)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	if len(rows.Columns) == 0 {
+		return 0, fmt.Errorf("query %q returned no columns", sql)
+	}
+
+	values := make([]driver.Value, len(rows.Columns))
+	if err := rows.Next(values); err != nil {
+		return 0, errors.Wrapf(err, "failed to fetch result of %q", sql)
+	}
+
+	value, ok := values[0].(int64)
+	if !ok {
+		return 0, fmt.Errorf("query %q returned a non-integer value", sql)
+	}
+
+	return value, nil
+}
+
 // Close the client.
 func (c *Client) Close() error {
 	return c.protocol.Close()
@@ -1,9 +1,20 @@
 package client
 
 import (
+	"context"
+	"net"
+
 	"github.com/canonical/go-dqlite/internal/protocol"
 )
 
 func (c *Client) Protocol() *protocol.Protocol {
 	return c.protocol
 }
+
+func SRVAddresses(records []*net.SRV) []string {
+	return srvAddresses(records)
+}
+
+func PutDump(ctx context.Context, store BackupStore, key string, files []File) error {
+	return putDump(ctx, store, key, files)
+}
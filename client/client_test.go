@@ -2,6 +2,7 @@ package client_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -96,6 +97,49 @@ func TestClient_Cluster(t *testing.T) {
 	assert.Equal(t, servers[0].Role, client.Voter)
 }
 
+func TestClient_Voters(t *testing.T) {
+	node1, cleanup := newNode(t)
+	defer cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	cli, err := client.New(ctx, node1.BindAddress())
+	require.NoError(t, err)
+	defer cli.Close()
+
+	_, cleanup = addNode(t, cli, 2)
+	defer cleanup()
+
+	voters, err := cli.Voters(context.Background())
+	require.NoError(t, err)
+
+	assert.Len(t, voters, 1)
+	assert.Equal(t, voters[0].ID, uint64(1))
+}
+
+func TestClient_NodeNotFound(t *testing.T) {
+	node1, cleanup := newNode(t)
+	defer cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	cli, err := client.New(ctx, node1.BindAddress())
+	require.NoError(t, err)
+	defer cli.Close()
+
+	err = cli.Remove(ctx, 123)
+	assert.True(t, errors.Is(err, client.ErrNodeNotFound))
+
+	err = cli.Assign(ctx, 123, client.Voter)
+	assert.True(t, errors.Is(err, client.ErrNodeNotFound))
+
+	err = cli.Assign(ctx, 1, client.Voter)
+	require.Error(t, err)
+	assert.False(t, errors.Is(err, client.ErrNodeNotFound))
+}
+
 func TestClient_Transfer(t *testing.T) {
 	node1, cleanup := newNode(t)
 	defer cleanup()
@@ -156,6 +200,48 @@ func TestClient_Describe(t *testing.T) {
 	assert.Equal(t, uint64(123), metadata.Weight)
 }
 
+func TestClient_CreateDatabase(t *testing.T) {
+	node, cleanup := newNode(t)
+	defer cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	client, err := client.New(ctx, node.BindAddress())
+	require.NoError(t, err)
+	defer client.Close()
+
+	require.NoError(t, client.CreateDatabase(ctx, "test.db"))
+
+	err = client.CreateDatabase(ctx, "test.db")
+	assert.EqualError(t, err, `database "test.db" already exists`)
+
+	err = client.CreateDatabase(ctx, "other.db")
+	assert.EqualError(t, err, `client has already opened database "test.db"`)
+}
+
+func TestClient_UserVersion(t *testing.T) {
+	node, cleanup := newNode(t)
+	defer cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	client, err := client.New(ctx, node.BindAddress())
+	require.NoError(t, err)
+	defer client.Close()
+
+	version, err := client.UserVersion(ctx, "test.db")
+	require.NoError(t, err)
+	assert.Equal(t, 0, version)
+
+	require.NoError(t, client.SetUserVersion(ctx, "test.db", 7))
+
+	version, err = client.UserVersion(ctx, "test.db")
+	require.NoError(t, err)
+	assert.Equal(t, 7, version)
+}
+
 func newNode(t *testing.T) (*dqlite.Node, func()) {
 	t.Helper()
 	dir, dirCleanup := newDir(t)
@@ -1,6 +1,8 @@
 package client
 
 import (
+	"fmt"
+
 	"github.com/canonical/go-dqlite/internal/protocol"
 )
 
@@ -10,3 +12,19 @@ const (
 	StandBy = protocol.StandBy
 	Spare   = protocol.Spare
 )
+
+// Errors that can be checked with errors.Is.
+var (
+	// ErrNoAvailableLeader is returned by FindLeader when no leader could
+	// be found.
+	ErrNoAvailableLeader = protocol.ErrNoAvailableLeader
+
+	// ErrNotLeader is matched by errors returned by requests that failed
+	// because the node the client is connected to isn't the leader, or
+	// lost leadership while serving the request.
+	ErrNotLeader = protocol.ErrNotLeader
+
+	// ErrNodeNotFound is matched by errors returned by Assign, Transfer
+	// and Remove when the cluster has no node with the given ID.
+	ErrNodeNotFound = fmt.Errorf("node not found")
+)
@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/tls"
 	"net"
+	"strings"
 
 	"github.com/canonical/go-dqlite/internal/protocol"
 )
@@ -14,6 +15,23 @@ func DefaultDialFunc(ctx context.Context, address string) (net.Conn, error) {
 	return protocol.Dial(ctx, address)
 }
 
+// UnixDialFunc is a dial function which, in addition to what DefaultDialFunc
+// supports, can dial Unix sockets bound to a filesystem path. Addresses
+// starting with "unix:" or "/" are dialed as Unix socket paths, and all other
+// addresses are handled by DefaultDialFunc.
+//
+// Note that the dqlite engine itself can only bind to TCP and abstract Unix
+// socket addresses (starting with "@"), so path-based sockets are useful only
+// when something else, e.g. a proxy, listens on them.
+func UnixDialFunc(ctx context.Context, address string) (net.Conn, error) {
+	path := strings.TrimPrefix(address, "unix:")
+	if path == address && !strings.HasPrefix(address, "/") {
+		return DefaultDialFunc(ctx, address)
+	}
+	dialer := net.Dialer{}
+	return dialer.DialContext(ctx, "unix", path)
+}
+
 // DialFuncWithTLS returns a dial function that uses TLS encryption.
 //
 // The given dial function will be used to establish the network connection,
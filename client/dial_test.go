@@ -0,0 +1,31 @@
+package client_test
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"testing"
+
+	"github.com/canonical/go-dqlite/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnixDialFunc(t *testing.T) {
+	dir, cleanup := newDir(t)
+	defer cleanup()
+
+	path := filepath.Join(dir, "dqlite.sock")
+	listener, err := net.Listen("unix", path)
+	require.NoError(t, err)
+	defer listener.Close()
+
+	for _, address := range []string{path, "unix:" + path} {
+		conn, err := client.UnixDialFunc(context.Background(), address)
+		require.NoError(t, err)
+		conn.Close()
+	}
+
+	_, err = client.UnixDialFunc(context.Background(), "@dqlite-unix-dial-test")
+	assert.Error(t, err)
+}
@@ -0,0 +1,62 @@
+package client
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ClusterDOT returns a Graphviz DOT representation of the given cluster nodes,
+// as returned by Client.Cluster().
+//
+// Each node is labeled with its ID, address and role, and filled with a color
+// depending on its role. The node with the given leader ID (if any) is drawn
+// with a bold double border and linked to all other nodes that replicate data
+// (voters and stand-bys).
+func ClusterDOT(nodes []NodeInfo, leaderID uint64) string {
+	var b strings.Builder
+
+	b.WriteString("digraph cluster {\n")
+	b.WriteString("\tnode [shape=box, style=filled];\n")
+
+	for _, node := range nodes {
+		attrs := fmt.Sprintf("label=%s, fillcolor=%s",
+			dotQuote(fmt.Sprintf("%x\n%s\n%s", node.ID, node.Address, node.Role)),
+			dotRoleColor(node.Role))
+		if node.ID == leaderID {
+			attrs += ", peripheries=2, penwidth=2"
+		}
+		fmt.Fprintf(&b, "\t\"%x\" [%s];\n", node.ID, attrs)
+	}
+
+	for _, node := range nodes {
+		if node.ID == leaderID || leaderID == 0 || node.Role == Spare {
+			continue
+		}
+		fmt.Fprintf(&b, "\t\"%x\" -> \"%x\";\n", leaderID, node.ID)
+	}
+
+	b.WriteString("}\n")
+
+	return b.String()
+}
+
+// Return the fill color used to draw a node with the given role.
+func dotRoleColor(role NodeRole) string {
+	switch role {
+	case Voter:
+		return "lightblue"
+	case StandBy:
+		return "lightyellow"
+	case Spare:
+		return "lightgrey"
+	default:
+		return "white"
+	}
+}
+
+// Quote the given string as a DOT ID, escaping double quotes and newlines.
+func dotQuote(s string) string {
+	s = strings.Replace(s, `"`, `\"`, -1)
+	s = strings.Replace(s, "\n", `\n`, -1)
+	return `"` + s + `"`
+}
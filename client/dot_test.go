@@ -0,0 +1,27 @@
+package client_test
+
+import (
+	"testing"
+
+	"github.com/canonical/go-dqlite/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClusterDOT(t *testing.T) {
+	nodes := []client.NodeInfo{
+		{ID: 1, Address: "@1001", Role: client.Voter},
+		{ID: 2, Address: "@1002", Role: client.StandBy},
+		{ID: 3, Address: "@1003", Role: client.Spare},
+	}
+
+	dot := client.ClusterDOT(nodes, 1)
+
+	assert.Equal(t, `digraph cluster {
+	node [shape=box, style=filled];
+	"1" [label="1\n@1001\nvoter", fillcolor=lightblue, peripheries=2, penwidth=2];
+	"2" [label="2\n@1002\nstand-by", fillcolor=lightyellow];
+	"3" [label="3\n@1003\nspare", fillcolor=lightgrey];
+	"1" -> "2";
+}
+`, dot)
+}
@@ -0,0 +1,100 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// DumpTo writes the content of the database with the given name to w, using
+// the archive format of WriteDump.
+//
+// This is useful to stream a backup somewhere (e.g. through a compressor)
+// without staging the dumped files in a temporary directory.
+func (c *Client) DumpTo(ctx context.Context, dbname string, w io.Writer) error {
+	files, err := c.Dump(ctx, dbname)
+	if err != nil {
+		return err
+	}
+	return WriteDump(w, files)
+}
+
+// WriteDump writes the given files to w as a simple archive.
+//
+// Each file is encoded as a header holding the length of its name and the
+// length of its data, both as 64-bit little endian integers, followed by the
+// name and then by the data.
+func WriteDump(w io.Writer, files []File) error {
+	for _, file := range files {
+		header := make([]byte, 16)
+		binary.LittleEndian.PutUint64(header[0:], uint64(len(file.Name)))
+		binary.LittleEndian.PutUint64(header[8:], uint64(len(file.Data)))
+		if _, err := w.Write(header); err != nil {
+			return errors.Wrapf(err, "write header of %s", file.Name)
+		}
+		if _, err := io.WriteString(w, file.Name); err != nil {
+			return errors.Wrapf(err, "write name of %s", file.Name)
+		}
+		if _, err := w.Write(file.Data); err != nil {
+			return errors.Wrapf(err, "write data of %s", file.Name)
+		}
+	}
+	return nil
+}
+
+// Maximum length of a file name in a dump archive.
+const maxDumpNameLen = 4096
+
+// MaxDumpDataLen is the maximum length of the data of a single file that
+// ReadDump accepts, matching the 1 TiB size that a dqlite database can
+// practically reach.
+const MaxDumpDataLen = 1 << 40
+
+// ReadDump reads back the files of an archive written by WriteDump.
+//
+// Data is buffered as it's read, so a corrupt header can't trigger an
+// allocation larger than the actual content of r.
+func ReadDump(r io.Reader) ([]File, error) {
+	files := make([]File, 0)
+	header := make([]byte, 16)
+
+	for {
+		if _, err := io.ReadFull(r, header); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, errors.Wrap(err, "read header")
+		}
+
+		nameLen := binary.LittleEndian.Uint64(header[0:])
+		dataLen := binary.LittleEndian.Uint64(header[8:])
+		if nameLen == 0 || nameLen > maxDumpNameLen {
+			return nil, fmt.Errorf("invalid file name length %d", nameLen)
+		}
+
+		name := make([]byte, nameLen)
+		if _, err := io.ReadFull(r, name); err != nil {
+			return nil, errors.Wrap(err, "read name")
+		}
+
+		if dataLen > MaxDumpDataLen {
+			return nil, fmt.Errorf("invalid data length %d for %s", dataLen, name)
+		}
+
+		data := bytes.NewBuffer(nil)
+		if _, err := io.CopyN(data, r, int64(dataLen)); err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, errors.Wrapf(err, "read data of %s", name)
+		}
+
+		files = append(files, File{Name: string(name), Data: data.Bytes()})
+	}
+
+	return files, nil
+}
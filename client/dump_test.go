@@ -0,0 +1,65 @@
+package client_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/canonical/go-dqlite/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteDump(t *testing.T) {
+	files := []client.File{
+		{Name: "test.db", Data: []byte("main")},
+		{Name: "test.db-wal", Data: []byte{}},
+	}
+
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, client.WriteDump(buf, files))
+
+	dump, err := client.ReadDump(buf)
+	require.NoError(t, err)
+
+	require.Len(t, dump, 2)
+	assert.Equal(t, "test.db", dump[0].Name)
+	assert.Equal(t, []byte("main"), dump[0].Data)
+	assert.Equal(t, "test.db-wal", dump[1].Name)
+	assert.Len(t, dump[1].Data, 0)
+}
+
+func TestReadDump_Truncated(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, client.WriteDump(buf, []client.File{{Name: "test.db", Data: []byte("main")}}))
+
+	_, err := client.ReadDump(bytes.NewReader(buf.Bytes()[:buf.Len()-1]))
+	assert.EqualError(t, err, "read data of test.db: unexpected EOF")
+}
+
+func TestReadDump_CorruptHeader(t *testing.T) {
+	cases := []struct {
+		title   string
+		dataLen uint64
+		err     string
+	}{
+		{"too large", math.MaxUint64, "invalid data length 18446744073709551615 for test.db"},
+		{"beyond content", 1 << 30, "read data of test.db: unexpected EOF"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			header := make([]byte, 16)
+			binary.LittleEndian.PutUint64(header[0:], 7)
+			binary.LittleEndian.PutUint64(header[8:], c.dataLen)
+
+			buf := bytes.NewBuffer(header)
+			buf.WriteString("test.db")
+			buf.WriteString("main")
+
+			_, err := client.ReadDump(buf)
+			assert.EqualError(t, err, c.err)
+		})
+	}
+}
@@ -11,7 +11,8 @@ import (
 // The function will iterate through to all nodes in the given store, and for
 // each of them check if it's the current leader. If no leader is found, the
 // function will keep retrying (with a capped exponential backoff) until the
-// given context is canceled.
+// given context is canceled or the retry limit set with WithRetryLimit is
+// reached.
 func FindLeader(ctx context.Context, store NodeStore, options ...Option) (*Client, error) {
 	o := defaultOptions()
 
@@ -20,7 +21,15 @@ func FindLeader(ctx context.Context, store NodeStore, options ...Option) (*Clien
 	}
 
 	config := protocol.Config{
-		Dial: o.DialFunc,
+		Dial:             o.DialFunc,
+		AttemptTimeout:   o.AttemptTimeout,
+		BackoffFactor:    o.BackoffFactor,
+		BackoffCap:       o.BackoffCap,
+		RetryLimit:       o.RetryLimit,
+		ParallelAttempts: o.Parallel,
+		CallTimeout:      o.CallTimeout,
+		Tracer:           o.Tracer,
+		FailureFunc:      o.FailureFunc,
 	}
 	connector := protocol.NewConnector(0, store, config, o.LogFunc)
 	protocol, err := connector.Connect(ctx)
@@ -44,3 +44,19 @@ func TestMembership(t *testing.T) {
 	err = client.Add(ctx, infos[1])
 	require.NoError(t, err)
 }
+
+// FindLeader gives up after the configured number of retries.
+func TestFindLeader_RetryLimit(t *testing.T) {
+	store := client.NewInmemNodeStore()
+	store.Set(context.Background(), []client.NodeInfo{{ID: 1, Address: "@test-unreachable"}})
+
+	attempts := 0
+	_, err := client.FindLeader(
+		context.Background(), store,
+		client.WithRetryLimit(2),
+		client.WithConnectionBackoffFactor(time.Millisecond),
+		client.WithConnectorFailureFunc(func(a []client.ConnectAttempt) { attempts = len(a) }),
+	)
+	require.Error(t, err)
+	require.Equal(t, 3, attempts)
+}
@@ -0,0 +1,284 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ErrPoolClosed is returned by Pool.Get after the pool has been closed.
+var ErrPoolClosed = fmt.Errorf("client pool is closed")
+
+// Pool keeps a set of idle clients connected to the cluster leader, so they
+// can be reused across operations instead of finding the leader every time.
+//
+// It's safe for concurrent use.
+type Pool struct {
+	store       NodeStore
+	options     []Option
+	maxIdle     int
+	maxOpen     int
+	idleTimeout time.Duration
+	mu          sync.Mutex
+	idle        []pooledClient
+	open        int           // Number of idle clients and clients in use.
+	freed       chan struct{} // Closed when a client is handed back or closed.
+	closed      bool
+}
+
+type pooledClient struct {
+	client *Client
+	since  time.Time // When the client was returned to the pool.
+}
+
+// PoolOption can be used to tweak pool parameters.
+type PoolOption func(*poolOptions)
+
+type poolOptions struct {
+	MaxIdle       int
+	MaxOpen       int
+	IdleTimeout   time.Duration
+	ClientOptions []Option
+}
+
+// WithPoolMaxIdle sets the maximum number of idle clients kept by the pool.
+//
+// If not used, the default is 2.
+func WithPoolMaxIdle(n int) PoolOption {
+	return func(options *poolOptions) {
+		options.MaxIdle = n
+	}
+}
+
+// WithPoolMaxOpen sets the maximum number of clients, idle or in use, that the
+// pool keeps open at the same time. When the limit is reached, Get waits for a
+// client to be handed back.
+//
+// If not used, or set to zero, there's no limit.
+func WithPoolMaxOpen(n int) PoolOption {
+	return func(options *poolOptions) {
+		options.MaxOpen = n
+	}
+}
+
+// WithPoolIdleTimeout sets how long a client can stay idle in the pool before
+// being closed. Zero means idle clients are never closed.
+//
+// If not used, the default is 1 minute.
+func WithPoolIdleTimeout(timeout time.Duration) PoolOption {
+	return func(options *poolOptions) {
+		options.IdleTimeout = timeout
+	}
+}
+
+// WithPoolClientOptions sets the options passed to FindLeader when the pool
+// needs a new client.
+func WithPoolClientOptions(options ...Option) PoolOption {
+	return func(o *poolOptions) {
+		o.ClientOptions = options
+	}
+}
+
+// NewPool creates a new pool of clients connected to the leader of the cluster
+// whose nodes are listed in the given store.
+func NewPool(store NodeStore, options ...PoolOption) *Pool {
+	o := &poolOptions{
+		MaxIdle:     2,
+		IdleTimeout: time.Minute,
+	}
+	for _, option := range options {
+		option(o)
+	}
+
+	return &Pool{
+		store:       store,
+		options:     o.ClientOptions,
+		maxIdle:     o.MaxIdle,
+		maxOpen:     o.MaxOpen,
+		idleTimeout: o.IdleTimeout,
+		freed:       make(chan struct{}),
+	}
+}
+
+// Get returns an idle client from the pool, or a new one connected to the
+// current leader if there's none.
+//
+// If the pool already has the maximum number of open clients, Get waits until
+// one is handed back or the given context is done.
+//
+// The client must be handed back with Put once done, or with Discard if it's
+// not usable anymore.
+func (p *Pool) Get(ctx context.Context) (*Client, error) {
+	for {
+		p.mu.Lock()
+		if p.closed {
+			p.mu.Unlock()
+			return nil, ErrPoolClosed
+		}
+
+		client, expired := p.popIdle()
+		if client != nil {
+			p.mu.Unlock()
+			closeClients(expired)
+			return client, nil
+		}
+
+		if p.maxOpen == 0 || p.open < p.maxOpen {
+			p.open++
+			p.mu.Unlock()
+			closeClients(expired)
+
+			client, err := FindLeader(ctx, p.store, p.options...)
+			if err != nil {
+				p.release()
+				return nil, err
+			}
+			return client, nil
+		}
+
+		freed := p.freed
+		p.mu.Unlock()
+		closeClients(expired)
+
+		select {
+		case <-freed:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// Pop the most recently used idle client that hasn't expired, if any, along
+// with the expired ones, which must be closed by the caller. Must be called
+// with the lock held.
+func (p *Pool) popIdle() (*Client, []*Client) {
+	var expired []*Client
+	for len(p.idle) > 0 {
+		n := len(p.idle) - 1
+		idle := p.idle[n]
+		p.idle = p.idle[:n]
+		if p.idleTimeout != 0 && time.Since(idle.since) > p.idleTimeout {
+			expired = append(expired, idle.client)
+			p.open--
+			p.notify()
+			continue
+		}
+		return idle.client, expired
+	}
+	return nil, expired
+}
+
+// Wake up the Get calls waiting for a client. Must be called with the lock
+// held.
+func (p *Pool) notify() {
+	close(p.freed)
+	p.freed = make(chan struct{})
+}
+
+// Account for a client that was closed or could not be created.
+func (p *Pool) release() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.open--
+	p.notify()
+}
+
+// Put hands back a client obtained with Get, making it available for reuse.
+//
+// If the pool is closed or already has the maximum number of idle clients, the
+// client is closed instead.
+func (p *Pool) Put(client *Client) {
+	p.mu.Lock()
+
+	if p.closed || len(p.idle) >= p.maxIdle {
+		p.open--
+		p.notify()
+		p.mu.Unlock()
+		client.Close()
+		return
+	}
+
+	p.idle = append(p.idle, pooledClient{client: client, since: time.Now()})
+	p.notify()
+	p.mu.Unlock()
+}
+
+// Discard closes a client obtained with Get which is not usable anymore, for
+// example because its connection broke, freeing its slot in the pool.
+func (p *Pool) Discard(client *Client) {
+	p.release()
+	client.Close()
+}
+
+// Do invokes f with a client from the pool.
+//
+// If f fails because the connection broke or the node lost leadership, the
+// client is discarded, so the next Get connects to the new leader. Otherwise
+// the client is handed back to the pool. The error returned by f is passed
+// through.
+func (p *Pool) Do(ctx context.Context, f func(*Client) error) error {
+	client, err := p.Get(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = f(client)
+	if err != nil && isConnError(err) {
+		p.Discard(client)
+		return err
+	}
+
+	p.Put(client)
+
+	return err
+}
+
+// Close closes all idle clients. Clients that are in use are closed when
+// they're handed back, and pending Get calls return ErrPoolClosed.
+func (p *Pool) Close() error {
+	p.mu.Lock()
+
+	p.closed = true
+	clients := make([]*Client, len(p.idle))
+	for i, idle := range p.idle {
+		clients[i] = idle.client
+	}
+	p.open -= len(p.idle)
+	p.idle = nil
+	p.notify()
+
+	p.mu.Unlock()
+
+	closeClients(clients)
+
+	return nil
+}
+
+func closeClients(clients []*Client) {
+	for _, client := range clients {
+		client.Close()
+	}
+}
+
+// Whether the given error means the client can't be reused.
+func isConnError(err error) bool {
+	if errors.Is(err, ErrNotLeader) {
+		return true
+	}
+	cause := errors.Cause(err)
+	if cause == io.EOF || cause == io.ErrUnexpectedEOF {
+		return true
+	}
+	switch cause.(type) {
+	case syscall.Errno, net.Error:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,201 @@
+package client_test
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/canonical/go-dqlite/client"
+	"github.com/canonical/go-dqlite/internal/protocol"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPool(t *testing.T) {
+	node, cleanup := newNode(t)
+	defer cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	store := client.NewInmemNodeStore()
+	require.NoError(t, store.Set(ctx, []client.NodeInfo{{ID: 1, Address: node.BindAddress()}}))
+
+	pool := client.NewPool(store)
+	defer pool.Close()
+
+	cli, err := pool.Get(ctx)
+	require.NoError(t, err)
+	pool.Put(cli)
+
+	// The idle client gets reused.
+	err = pool.Do(ctx, func(c *client.Client) error {
+		assert.Equal(t, cli, c)
+		_, err := c.Leader(ctx)
+		return err
+	})
+	require.NoError(t, err)
+}
+
+func TestPool_Closed(t *testing.T) {
+	pool := client.NewPool(client.NewInmemNodeStore())
+	require.NoError(t, pool.Close())
+
+	_, err := pool.Get(context.Background())
+	assert.Equal(t, client.ErrPoolClosed, err)
+}
+
+// When the maximum number of clients is open, Get waits for one to be handed
+// back.
+func TestPool_MaxOpen(t *testing.T) {
+	node, cleanup := newNode(t)
+	defer cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	store := client.NewInmemNodeStore()
+	require.NoError(t, store.Set(ctx, []client.NodeInfo{{ID: 1, Address: node.BindAddress()}}))
+
+	pool := client.NewPool(store, client.WithPoolMaxOpen(1))
+	defer pool.Close()
+
+	cli, err := pool.Get(ctx)
+	require.NoError(t, err)
+
+	waitCtx, waitCancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer waitCancel()
+	_, err = pool.Get(waitCtx)
+	assert.Equal(t, context.DeadlineExceeded, err)
+
+	go pool.Put(cli)
+
+	other, err := pool.Get(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, cli, other)
+	pool.Put(other)
+}
+
+// A failure to create a client frees its slot.
+func TestPool_MaxOpenFailure(t *testing.T) {
+	pool := client.NewPool(client.NewInmemNodeStore(),
+		client.WithPoolMaxOpen(1),
+		client.WithPoolClientOptions(client.WithRetryLimit(1)),
+	)
+	defer pool.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	for i := 0; i < 2; i++ {
+		_, err := pool.Get(ctx)
+		assert.Equal(t, client.ErrNoAvailableLeader, errors.Cause(err))
+	}
+}
+
+// If the server closes the connection, the broken client is discarded and
+// the next Do gets a new one.
+func TestPool_ConnectionClosed(t *testing.T) {
+	address, conns, cleanup := newClosingServer(t)
+	defer cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	store := client.NewInmemNodeStore()
+	require.NoError(t, store.Set(ctx, []client.NodeInfo{{ID: 1, Address: address}}))
+
+	pool := client.NewPool(store)
+	defer pool.Close()
+
+	leader := func(c *client.Client) error {
+		_, err := c.Leader(ctx)
+		return err
+	}
+
+	assert.Error(t, pool.Do(ctx, leader))
+	assert.NoError(t, pool.Do(ctx, leader))
+	assert.Equal(t, int32(2), atomic.LoadInt32(conns))
+}
+
+// Start a fake dqlite server which reports itself as leader and registers
+// clients, but closes the first connection as soon as it gets a request after
+// registration. Return its address and a counter of accepted connections.
+func newClosingServer(t *testing.T) (string, *int32, func()) {
+	t.Helper()
+
+	listener, err := net.Listen("unix", "@test-pool-closing")
+	require.NoError(t, err)
+	address := listener.Addr().String()
+
+	// Node response body: the leader ID and its address, as a string
+	// padded to a word boundary.
+	name := make([]byte, (len(address)/8+1)*8)
+	copy(name, address)
+	node := make([]byte, 8+len(name))
+	binary.LittleEndian.PutUint64(node, 1)
+	copy(node[8:], name)
+
+	// Welcome response body: the heartbeat timeout.
+	welcome := make([]byte, 8)
+
+	respond := func(conn net.Conn, mtype uint8, body []byte) {
+		header := make([]byte, 8)
+		binary.LittleEndian.PutUint32(header, uint32(len(body)/8))
+		header[4] = mtype
+		conn.Write(append(header, body...))
+	}
+
+	conns := new(int32)
+	serve := func(conn net.Conn, closing bool) {
+		defer conn.Close()
+
+		handshake := make([]byte, 8)
+		if _, err := io.ReadFull(conn, handshake); err != nil {
+			return
+		}
+
+		registered := false
+		for {
+			header := make([]byte, 8)
+			if _, err := io.ReadFull(conn, header); err != nil {
+				return
+			}
+			words := binary.LittleEndian.Uint32(header)
+			if _, err := io.CopyN(ioutil.Discard, conn, int64(words)*8); err != nil {
+				return
+			}
+			if registered && closing {
+				return
+			}
+			switch header[4] {
+			case protocol.RequestLeader:
+				respond(conn, protocol.ResponseNode, node)
+			case protocol.RequestClient:
+				respond(conn, protocol.ResponseWelcome, welcome)
+				registered = true
+			default:
+				return
+			}
+		}
+	}
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			n := atomic.AddInt32(conns, 1)
+			go serve(conn, n == 1)
+		}
+	}()
+
+	return address, conns, func() { listener.Close() }
+}
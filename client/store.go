@@ -5,9 +5,13 @@ import (
 	"database/sql"
 	"fmt"
 	"io/ioutil"
+	"net"
 	"os"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/google/renameio"
 	"github.com/pkg/errors"
@@ -33,6 +37,17 @@ type InmemNodeStore = protocol.InmemNodeStore
 // NewInmemNodeStore creates NodeStore which stores its data in-memory.
 var NewInmemNodeStore = protocol.NewInmemNodeStore
 
+// LeaderTracker can be implemented by a NodeStore to have FindLeader try the
+// last leader it found first.
+type LeaderTracker = protocol.LeaderTracker
+
+// LeaderCacheStore wraps a NodeStore, remembering the last leader found
+// through it so that it's tried first when connecting again.
+type LeaderCacheStore = protocol.LeaderCacheStore
+
+// NewLeaderCacheStore creates a LeaderCacheStore wrapping the given store.
+var NewLeaderCacheStore = protocol.NewLeaderCacheStore
+
 // DatabaseNodeStore persists a list addresses of dqlite nodes in a SQL table.
 type DatabaseNodeStore struct {
 	db     *sql.DB // Database handle to use.
@@ -108,6 +123,10 @@ func NewNodeStore(db *sql.DB, schema, table, column string, options ...NodeStore
 }
 
 // Get the current servers.
+//
+// If the servers table doesn't exist yet, an empty list is returned, so the
+// store can be used before the table gets created (e.g. while bootstrapping
+// the cluster that will host it).
 func (d *DatabaseNodeStore) Get(ctx context.Context) ([]NodeInfo, error) {
 	tx, err := d.db.Begin()
 	if err != nil {
@@ -121,6 +140,9 @@ func (d *DatabaseNodeStore) Get(ctx context.Context) ([]NodeInfo, error) {
 	}
 	rows, err := tx.QueryContext(ctx, query)
 	if err != nil {
+		if strings.HasPrefix(err.Error(), "no such table") {
+			return []NodeInfo{}, nil
+		}
 		return nil, errors.Wrap(err, "failed to query servers table")
 	}
 	defer rows.Close()
@@ -238,3 +260,162 @@ func (s *YamlNodeStore) Set(ctx context.Context, servers []NodeInfo) error {
 
 	return nil
 }
+
+// DNSNodeStore resolves the addresses of dqlite nodes from a DNS name, either
+// from its A/AAAA records (see NewDNSNodeStore) or from its SRV records (see
+// NewDNSSRVNodeStore).
+//
+// This is useful in environments like Kubernetes, where the addresses of the
+// nodes change over time but a DNS name (e.g. of a headless service) that
+// resolves to all of them is stable.
+type DNSNodeStore struct {
+	name     string
+	lookup   func(ctx context.Context, resolver *net.Resolver) ([]string, error)
+	ttl      time.Duration
+	idFunc   func(address string) uint64
+	resolver *net.Resolver
+	servers  []NodeInfo
+	expiry   time.Time
+	mu       sync.Mutex
+}
+
+// DNSNodeStoreOption can be used to tweak DNS node store parameters.
+type DNSNodeStoreOption func(*dnsNodeStoreOptions)
+
+type dnsNodeStoreOptions struct {
+	TTL      time.Duration
+	IDFunc   func(address string) uint64
+	Resolver *net.Resolver
+}
+
+// WithDNSNodeStoreTTL sets how long resolved addresses are cached before the
+// name is resolved again. The default is 5 seconds, zero disables caching.
+func WithDNSNodeStoreTTL(ttl time.Duration) DNSNodeStoreOption {
+	return func(options *dnsNodeStoreOptions) {
+		options.TTL = ttl
+	}
+}
+
+// WithDNSNodeStoreIDFunc sets a function used to derive the ID of a node from
+// its address (e.g. by mapping it to a stable ordinal).
+//
+// Node IDs can't be obtained from DNS, so by default they're all zero. That's
+// enough for finding the leader, which only needs addresses.
+//
+// The function may be called concurrently by different Get calls.
+func WithDNSNodeStoreIDFunc(f func(address string) uint64) DNSNodeStoreOption {
+	return func(options *dnsNodeStoreOptions) {
+		options.IDFunc = f
+	}
+}
+
+// WithDNSNodeStoreResolver sets the resolver to use. The default is
+// net.DefaultResolver.
+func WithDNSNodeStoreResolver(resolver *net.Resolver) DNSNodeStoreOption {
+	return func(options *dnsNodeStoreOptions) {
+		options.Resolver = resolver
+	}
+}
+
+// NewDNSNodeStore creates a new DNSNodeStore which resolves the A and AAAA
+// records of the given name and uses the given port for all the resulting
+// addresses.
+func NewDNSNodeStore(name string, port int, options ...DNSNodeStoreOption) *DNSNodeStore {
+	p := strconv.Itoa(port)
+	lookup := func(ctx context.Context, resolver *net.Resolver) ([]string, error) {
+		hosts, err := resolver.LookupHost(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		addresses := make([]string, len(hosts))
+		for i, host := range hosts {
+			addresses[i] = net.JoinHostPort(host, p)
+		}
+		return addresses, nil
+	}
+	return newDNSNodeStore(name, lookup, options...)
+}
+
+// NewDNSSRVNodeStore creates a new DNSNodeStore which resolves the SRV records
+// of the given service, protocol and name, as in net.LookupSRV, taking the
+// host and port of each node from its record.
+//
+// In Kubernetes, a headless service with a port named "dqlite" can be
+// resolved with NewDNSSRVNodeStore("dqlite", "tcp", "<service>").
+func NewDNSSRVNodeStore(service, proto, name string, options ...DNSNodeStoreOption) *DNSNodeStore {
+	lookup := func(ctx context.Context, resolver *net.Resolver) ([]string, error) {
+		_, records, err := resolver.LookupSRV(ctx, service, proto, name)
+		if err != nil {
+			return nil, err
+		}
+		return srvAddresses(records), nil
+	}
+	return newDNSNodeStore(name, lookup, options...)
+}
+
+func newDNSNodeStore(name string, lookup func(context.Context, *net.Resolver) ([]string, error), options ...DNSNodeStoreOption) *DNSNodeStore {
+	o := &dnsNodeStoreOptions{
+		TTL:      5 * time.Second,
+		IDFunc:   func(string) uint64 { return 0 },
+		Resolver: net.DefaultResolver,
+	}
+	for _, option := range options {
+		option(o)
+	}
+
+	return &DNSNodeStore{
+		name:     name,
+		lookup:   lookup,
+		ttl:      o.TTL,
+		idFunc:   o.IDFunc,
+		resolver: o.Resolver,
+	}
+}
+
+// Convert SRV records to node addresses.
+func srvAddresses(records []*net.SRV) []string {
+	addresses := make([]string, len(records))
+	for i, record := range records {
+		host := strings.TrimSuffix(record.Target, ".")
+		addresses[i] = net.JoinHostPort(host, strconv.Itoa(int(record.Port)))
+	}
+	return addresses
+}
+
+// Get the current servers, resolving the name again if the cached addresses
+// have expired.
+//
+// The name is resolved without holding the store lock, so a slow resolver
+// doesn't block concurrent calls that can be served from the cache.
+func (s *DNSNodeStore) Get(ctx context.Context) ([]NodeInfo, error) {
+	s.mu.Lock()
+	servers, expiry := s.servers, s.expiry
+	s.mu.Unlock()
+
+	if servers == nil || !time.Now().Before(expiry) {
+		addresses, err := s.lookup(ctx, s.resolver)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to resolve %s", s.name)
+		}
+		sort.Strings(addresses)
+
+		servers = make([]NodeInfo, len(addresses))
+		for i, address := range addresses {
+			servers[i] = NodeInfo{ID: s.idFunc(address), Address: address}
+		}
+
+		s.mu.Lock()
+		s.servers = servers
+		s.expiry = time.Now().Add(s.ttl)
+		s.mu.Unlock()
+	}
+
+	ret := make([]NodeInfo, len(servers))
+	copy(ret, servers)
+	return ret, nil
+}
+
+// Set is a no-op, since the servers are determined by DNS.
+func (s *DNSNodeStore) Set(ctx context.Context, servers []NodeInfo) error {
+	return nil
+}
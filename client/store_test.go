@@ -2,6 +2,8 @@ package client_test
 
 import (
 	"context"
+	"database/sql"
+	"net"
 	"testing"
 
 	"github.com/canonical/go-dqlite/client"
@@ -53,3 +55,48 @@ func TestDefaultNodeStore(t *testing.T) {
 		{ID: uint64(1), Address: "9.9.9.9:666"}},
 		servers)
 }
+
+// If the servers table doesn't exist, Get returns an empty list.
+func TestDatabaseNodeStore_NoTable(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	store := client.NewNodeStore(db, "main", "servers", "address")
+
+	servers, err := store.Get(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, servers, 0)
+}
+
+// A DNSNodeStore resolves its name and caches the result.
+func TestDNSNodeStore(t *testing.T) {
+	ids := 0
+	idFunc := func(address string) uint64 {
+		ids++
+		return uint64(ids)
+	}
+	store := client.NewDNSNodeStore("127.0.0.1", 9001, client.WithDNSNodeStoreIDFunc(idFunc))
+
+	servers, err := store.Get(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []client.NodeInfo{{ID: 1, Address: "127.0.0.1:9001"}}, servers)
+
+	// The second call hits the cache.
+	servers, err = store.Get(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []client.NodeInfo{{ID: 1, Address: "127.0.0.1:9001"}}, servers)
+}
+
+// SRV records are converted to addresses using their target and port.
+func TestSRVAddresses(t *testing.T) {
+	records := []*net.SRV{
+		{Target: "dqlite-0.dqlite.default.svc.cluster.local.", Port: 9001},
+		{Target: "::1", Port: 9002},
+	}
+
+	assert.Equal(t, []string{
+		"dqlite-0.dqlite.default.svc.cluster.local:9001",
+		"[::1]:9002",
+	}, client.SRVAddresses(records))
+}
@@ -35,6 +35,20 @@ func ConfigMultiThread() error {
 	return nil
 }
 
+// ConfigSoftHeapLimit sets a soft limit on the amount of heap memory that can
+// be allocated by SQLite, and returns the previous limit.
+//
+// When the limit is exceeded SQLite tries to free memory, for example by
+// evicting unused pages from database page caches, which keeps the total
+// memory used by the page caches of all databases bounded. A limit of zero
+// means no limit, and a negative limit just returns the current one.
+//
+// IMPORTANT: The limit is global to the whole process, so it applies to all
+// nodes running in it, as well as to any other user of SQLite.
+func ConfigSoftHeapLimit(limit int64) int64 {
+	return bindings.SetSoftHeapLimit(limit)
+}
+
 func init() {
 	// Don't enable single thread mode by default if GO_DQLITE_MULTITHREAD
 	// is set.
@@ -0,0 +1,14 @@
+package dqlite
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigSoftHeapLimit(t *testing.T) {
+	previous := ConfigSoftHeapLimit(1 << 20)
+	defer ConfigSoftHeapLimit(previous)
+
+	assert.Equal(t, int64(1<<20), ConfigSoftHeapLimit(-1))
+}
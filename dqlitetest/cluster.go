@@ -0,0 +1,175 @@
+// Package dqlitetest provides utilities for testing code that uses dqlite.
+package dqlitetest
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/canonical/go-dqlite"
+	"github.com/canonical/go-dqlite/client"
+)
+
+// Cluster is a dqlite cluster whose nodes run in the current process and
+// listen on abstract Unix sockets.
+type Cluster struct {
+	t     testing.TB
+	nodes map[uint64]*dqlite.Node // Nodes that are still running.
+	dirs  []string
+	store client.NodeStore
+}
+
+// Used to generate unique socket addresses across clusters.
+var clusters uint32
+
+// NewCluster creates a new cluster of n nodes, all with the Voter role.
+//
+// Node 1 bootstraps the cluster and the other nodes are then added to it, so
+// node IDs go from 1 to n. The cluster must be closed with Close when done.
+func NewCluster(t testing.TB, n int) *Cluster {
+	t.Helper()
+
+	c := &Cluster{
+		t:     t,
+		nodes: make(map[uint64]*dqlite.Node),
+		store: client.NewInmemNodeStore(),
+	}
+
+	prefix := fmt.Sprintf("@dqlitetest-%d-%d", os.Getpid(), atomic.AddUint32(&clusters, 1))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	infos := make([]client.NodeInfo, n)
+	for i := range infos {
+		id := uint64(i + 1)
+		address := fmt.Sprintf("%s-%d", prefix, id)
+		infos[i] = client.NodeInfo{ID: id, Address: address, Role: client.Voter}
+
+		dir, err := ioutil.TempDir("", "dqlitetest-")
+		if err != nil {
+			c.fatal("create data directory: %v", err)
+		}
+		c.dirs = append(c.dirs, dir)
+
+		node, err := dqlite.New(id, address, dir, dqlite.WithBindAddress(address))
+		if err != nil {
+			c.fatal("create node %d: %v", id, err)
+		}
+		c.nodes[id] = node
+
+		if err := node.Start(); err != nil {
+			c.fatal("start node %d: %v", id, err)
+		}
+
+		if id == 1 {
+			if err := c.store.Set(ctx, infos[:1]); err != nil {
+				c.fatal("set node store: %v", err)
+			}
+			continue
+		}
+
+		cli, err := client.FindLeader(ctx, c.store)
+		if err != nil {
+			c.fatal("find leader: %v", err)
+		}
+		info := client.NodeInfo{ID: id, Address: address, Role: client.Spare}
+		err = cli.Add(ctx, info)
+		if err == nil {
+			err = cli.Assign(ctx, id, client.Voter)
+		}
+		cli.Close()
+		if err != nil {
+			c.fatal("add node %d: %v", id, err)
+		}
+
+		if err := c.store.Set(ctx, infos[:i+1]); err != nil {
+			c.fatal("set node store: %v", err)
+		}
+	}
+
+	return c
+}
+
+// Store returns a node store holding all the nodes of the cluster.
+func (c *Cluster) Store() client.NodeStore {
+	return c.store
+}
+
+// Node returns the node with the given ID, or nil if it was killed.
+func (c *Cluster) Node(id uint64) *dqlite.Node {
+	return c.nodes[id]
+}
+
+// Leader returns the current leader, failing if it can't be found quickly,
+// e.g. because an election is in progress.
+func (c *Cluster) Leader(ctx context.Context) (*client.NodeInfo, error) {
+	return c.leader(ctx, client.WithRetryLimit(1))
+}
+
+// WaitForLeader returns the current leader, waiting for one to be elected if
+// needed, until the given context is done.
+func (c *Cluster) WaitForLeader(ctx context.Context) (*client.NodeInfo, error) {
+	return c.leader(ctx)
+}
+
+// KillLeader stops the current leader and returns its ID. The remaining nodes
+// will elect a new leader if they still have a quorum.
+func (c *Cluster) KillLeader(ctx context.Context) (uint64, error) {
+	leader, err := c.WaitForLeader(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	node, ok := c.nodes[leader.ID]
+	if !ok {
+		return 0, fmt.Errorf("leader %d was already killed", leader.ID)
+	}
+	delete(c.nodes, leader.ID)
+
+	if err := node.Close(); err != nil {
+		return 0, fmt.Errorf("stop node %d: %w", leader.ID, err)
+	}
+
+	return leader.ID, nil
+}
+
+// Close stops all the nodes of the cluster and removes their data.
+func (c *Cluster) Close() {
+	c.t.Helper()
+
+	for id, node := range c.nodes {
+		if err := node.Close(); err != nil {
+			c.t.Errorf("stop node %d: %v", id, err)
+		}
+	}
+	c.nodes = nil
+
+	for _, dir := range c.dirs {
+		if err := os.RemoveAll(dir); err != nil {
+			c.t.Errorf("remove data directory: %v", err)
+		}
+	}
+	c.dirs = nil
+}
+
+func (c *Cluster) leader(ctx context.Context, options ...client.Option) (*client.NodeInfo, error) {
+	cli, err := client.FindLeader(ctx, c.store, options...)
+	if err != nil {
+		return nil, err
+	}
+	defer cli.Close()
+
+	return cli.Leader(ctx)
+}
+
+// Release all resources and abort the test.
+func (c *Cluster) fatal(format string, args ...interface{}) {
+	c.t.Helper()
+	c.Close()
+	c.t.Fatalf(format, args...)
+}
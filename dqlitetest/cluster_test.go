@@ -0,0 +1,33 @@
+package dqlitetest_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/canonical/go-dqlite/dqlitetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Killing the leader of a three-node cluster triggers the election of a new
+// one.
+func TestCluster_Failover(t *testing.T) {
+	cluster := dqlitetest.NewCluster(t, 3)
+	defer cluster.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	leader, err := cluster.Leader(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), leader.ID)
+
+	killed, err := cluster.KillLeader(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, cluster.Node(killed))
+
+	leader, err = cluster.WaitForLeader(ctx)
+	require.NoError(t, err)
+	assert.NotEqual(t, killed, leader.ID)
+}
@@ -46,18 +46,10 @@ type Error = protocol.Error
 
 // Error codes. Values here mostly overlap with native SQLite codes.
 const (
-	ErrBusy                = 5
-	ErrBusyRecovery        = 5 | (1 << 8)
-	ErrBusySnapshot        = 5 | (2 << 8)
-	errIoErr               = 10
-	errIoErrNotLeader      = errIoErr | 40<<8
-	errIoErrLeadershipLost = errIoErr | (41 << 8)
-	errNotFound            = 12
-
-	// Legacy error codes before version-3.32.1+replication4. Kept here
-	// for backward compatibility, but should eventually be dropped.
-	errIoErrNotLeaderLegacy      = errIoErr | 32<<8
-	errIoErrLeadershipLostLegacy = errIoErr | (33 << 8)
+	ErrBusy         = 5
+	ErrBusyRecovery = 5 | (1 << 8)
+	ErrBusySnapshot = 5 | (2 << 8)
+	errNotFound     = 12
 )
 
 // Max amount of parameters in a Tuple.
@@ -149,6 +141,50 @@ func WithRetryLimit(limit uint) Option {
 	}
 }
 
+// WithParallelAttempts sets how many servers are probed concurrently when
+// looking for the leader. The first leader connection that is established is
+// used, and the other attempts are cancelled.
+//
+// If not used, the default is to probe one server at a time.
+func WithParallelAttempts(n int) Option {
+	return func(options *options) {
+		options.ParallelAttempts = n
+	}
+}
+
+// WithCallTimeout sets a timeout for each individual request sent to the
+// leader, so a hung node can't block a statement forever even if its context
+// has no deadline. A connection whose request timed out is discarded and
+// replaced by database/sql.
+//
+// If not used, the default is no timeout.
+func WithCallTimeout(timeout time.Duration) Option {
+	return func(options *options) {
+		options.CallTimeout = timeout
+	}
+}
+
+// WithTracer sets a tracer that gets a span for each attempt to find the
+// leader and for each request sent to it. Unlike WithTracing, which logs
+// statements, this is meant to feed a distributed tracing system.
+func WithTracer(tracer client.Tracer) Option {
+	return func(options *options) {
+		options.Tracer = tracer
+	}
+}
+
+// WithConnectorFailureFunc sets a function that is invoked when the driver
+// gives up trying to connect to the leader, either because the retry limit was
+// reached or because the context is done.
+//
+// The function receives all the failed attempts that were made, in order, so
+// it can be used to report details about the failure.
+func WithConnectorFailureFunc(f func(attempts []client.ConnectAttempt)) Option {
+	return func(options *options) {
+		options.FailureFunc = f
+	}
+}
+
 // WithContext sets a global cancellation context.
 //
 // DEPRECATED: This API is no a no-op. Users should explicitly pass a context
@@ -195,11 +231,15 @@ func New(store client.NodeStore, options ...Option) (*Driver, error) {
 		contextTimeout:    o.ContextTimeout,
 		tracing:           o.Tracing,
 		clientConfig: protocol.Config{
-			Dial:           o.Dial,
-			AttemptTimeout: o.AttemptTimeout,
-			BackoffFactor:  o.ConnectionBackoffFactor,
-			BackoffCap:     o.ConnectionBackoffCap,
-			RetryLimit:     o.RetryLimit,
+			Dial:             o.Dial,
+			AttemptTimeout:   o.AttemptTimeout,
+			BackoffFactor:    o.ConnectionBackoffFactor,
+			BackoffCap:       o.ConnectionBackoffCap,
+			RetryLimit:       o.RetryLimit,
+			ParallelAttempts: o.ParallelAttempts,
+			CallTimeout:      o.CallTimeout,
+			FailureFunc:      o.FailureFunc,
+			Tracer:           o.Tracer,
 		},
 	}
 
@@ -216,6 +256,10 @@ type options struct {
 	ConnectionBackoffFactor time.Duration
 	ConnectionBackoffCap    time.Duration
 	RetryLimit              uint
+	ParallelAttempts        int
+	CallTimeout             time.Duration
+	FailureFunc             func([]client.ConnectAttempt)
+	Tracer                  client.Tracer
 	Context                 context.Context
 	Tracing                 client.LogLevel
 }
@@ -764,13 +808,13 @@ func driverError(log client.LogFunc, err error) error {
 		return driver.ErrBadConn
 	case protocol.ErrRequest:
 		switch err.Code {
-		case errIoErrNotLeaderLegacy:
+		case protocol.ErrIoErrNotLeaderLegacy:
 			fallthrough
-		case errIoErrLeadershipLostLegacy:
+		case protocol.ErrIoErrLeadershipLostLegacy:
 			fallthrough
-		case errIoErrNotLeader:
+		case protocol.ErrIoErrNotLeader:
 			fallthrough
-		case errIoErrLeadershipLost:
+		case protocol.ErrIoErrLeadershipLost:
 			log(client.LogDebug, "leadership lost (%d - %s)", err.Code, err.Description)
 			return driver.ErrBadConn
 		case errNotFound:
@@ -0,0 +1,112 @@
+package dqlite
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Orphans returns the names of the files in the node's data directory that
+// were left behind by interrupted operations, such as a crash in the middle of
+// writing a snapshot.
+//
+// The check is conservative and only reports files matching well-known
+// patterns:
+//
+// - temporary files of incomplete writes (with a "tmp-" prefix);
+// - snapshot files whose ".meta" counterpart is missing, or vice versa.
+//
+// Any other file in the directory (open and closed raft segments, metadata
+// files, complete snapshots, database files, files created by the
+// application) is never reported.
+func (s *Node) Orphans() ([]string, error) {
+	return findOrphans(s.dir)
+}
+
+// CleanOrphans removes the files returned by Orphans.
+//
+// Since the engine might be in the middle of writing one of those files, this
+// method can only be called before the node is started or after it has been
+// closed, otherwise an error is returned.
+//
+// After the node has been closed, the data directory lock is taken again while
+// removing files, and ErrDirectoryLocked is returned if another node is using
+// the directory in the meantime.
+func (s *Node) CleanOrphans() error {
+	if s.started {
+		return fmt.Errorf("can't clean orphans while the node is running")
+	}
+
+	if s.closed {
+		lock, err := lockDir(s.dir)
+		if err != nil {
+			return err
+		}
+		defer lock.Close()
+	}
+
+	orphans, err := findOrphans(s.dir)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range orphans {
+		if err := os.Remove(filepath.Join(s.dir, name)); err != nil && !os.IsNotExist(err) {
+			return errors.Wrapf(err, "remove orphan %s", name)
+		}
+	}
+
+	return nil
+}
+
+// Prefix of temporary files created by the raft engine while writing a file
+// atomically.
+const tmpFilePrefix = "tmp-"
+
+// Suffix of snapshot metadata files.
+const snapshotMetaSuffix = ".meta"
+
+// Match snapshot data files, as written by the raft engine.
+var snapshotRe = regexp.MustCompile(`^snapshot-\d+-\d+-\d+$`)
+
+// Return the names of orphaned files in the given data directory.
+func findOrphans(dir string) ([]string, error) {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, errors.Wrap(err, "read data directory")
+	}
+
+	names := map[string]bool{}
+	for _, entry := range entries {
+		if entry.Mode().IsRegular() {
+			names[entry.Name()] = true
+		}
+	}
+
+	orphans := []string{}
+	for name := range names {
+		switch {
+		case strings.HasPrefix(name, tmpFilePrefix):
+			orphans = append(orphans, name)
+		case snapshotRe.MatchString(name):
+			if !names[name+snapshotMetaSuffix] {
+				orphans = append(orphans, name)
+			}
+		case strings.HasSuffix(name, snapshotMetaSuffix):
+			data := strings.TrimSuffix(name, snapshotMetaSuffix)
+			if snapshotRe.MatchString(data) && !names[data] {
+				orphans = append(orphans, name)
+			}
+		}
+	}
+
+	sort.Strings(orphans)
+
+	return orphans, nil
+}
@@ -0,0 +1,93 @@
+package dqlite
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindOrphans(t *testing.T) {
+	dir, cleanup := newDir(t)
+	defer cleanup()
+
+	for _, name := range []string{
+		"metadata1",
+		"metadata2",
+		"open-1",
+		"0000000000000001-0000000000000003",
+		"snapshot-1-3-100",
+		"snapshot-1-3-100.meta",
+		"snapshot-1-5-200",
+		"snapshot-1-7-300.meta",
+		"tmp-snapshot-1-9-400",
+		"info.yaml",
+		"foo.meta",
+	} {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), nil, 0600))
+	}
+
+	orphans, err := findOrphans(dir)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"snapshot-1-5-200",
+		"snapshot-1-7-300.meta",
+		"tmp-snapshot-1-9-400",
+	}, orphans)
+}
+
+func TestFindOrphans_Empty(t *testing.T) {
+	dir, cleanup := newDir(t)
+	defer cleanup()
+
+	orphans, err := findOrphans(dir)
+	require.NoError(t, err)
+	assert.Empty(t, orphans)
+}
+
+// After Close, orphans are removed only if the data directory can be locked
+// again.
+func TestCleanOrphans_Closed(t *testing.T) {
+	dir, cleanup := newDir(t)
+	defer cleanup()
+
+	orphan := filepath.Join(dir, "tmp-snapshot-1-9-400")
+	require.NoError(t, ioutil.WriteFile(orphan, nil, 0600))
+
+	node := &Node{dir: dir, closed: true}
+
+	lock, err := lockDir(dir)
+	require.NoError(t, err)
+
+	assert.Equal(t, ErrDirectoryLocked, node.CleanOrphans())
+	assert.FileExists(t, orphan)
+
+	require.NoError(t, lock.Close())
+
+	require.NoError(t, node.CleanOrphans())
+	_, err = os.Stat(orphan)
+	assert.True(t, os.IsNotExist(err))
+
+	// The lock was released.
+	lock, err = lockDir(dir)
+	require.NoError(t, err)
+	require.NoError(t, lock.Close())
+}
+
+// Return a new temporary directory.
+func newDir(t *testing.T) (string, func()) {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "dqlite-node-test-")
+	assert.NoError(t, err)
+
+	cleanup := func() {
+		assert.NoError(t, os.RemoveAll(dir))
+	}
+
+	return dir, cleanup
+}
@@ -116,6 +116,26 @@ func ConfigMultiThread() error {
 	return nil
 }
 
+// SQLiteVersion returns the version of the linked SQLite library.
+func SQLiteVersion() string {
+	return C.GoString(C.sqlite3_libversion())
+}
+
+// DqliteVersion returns the version number of the linked dqlite library.
+func DqliteVersion() int {
+	return int(C.dqlite_version_number())
+}
+
+// RaftVersion returns the version number of the linked raft library.
+func RaftVersion() int {
+	return int(C.raft_version_number())
+}
+
+// SetSoftHeapLimit sets SQLite's soft heap limit and returns the previous one.
+func SetSoftHeapLimit(limit int64) int64 {
+	return int64(C.sqlite3_soft_heap_limit64(C.sqlite3_int64(limit)))
+}
+
 // NewNode creates a new Node instance.
 func NewNode(ctx context.Context, id uint64, address string, dir string) (*Node, error) {
 	var server *C.dqlite_node
@@ -190,6 +210,14 @@ func (s *Node) SetFailureDomain(code uint64) error {
 	return nil
 }
 
+func (s *Node) SetSnapshotCompression(enabled bool) error {
+	server := (*C.dqlite_node)(unsafe.Pointer(s.node))
+	if rc := C.dqlite_node_set_snapshot_compression(server, C.bool(enabled)); rc != 0 {
+		return fmt.Errorf("failed to set snapshot compression")
+	}
+	return nil
+}
+
 func (s *Node) EnableDiskMode() error {
 	server := (*C.dqlite_node)(unsafe.Pointer(s.node))
 	if rc := C.dqlite_node_enable_disk_mode(server); rc != 0 {
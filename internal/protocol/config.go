@@ -6,10 +6,26 @@ import (
 
 // Config holds various configuration parameters for a dqlite client.
 type Config struct {
-	Dial           DialFunc      // Network dialer.
-	DialTimeout    time.Duration // Timeout for establishing a network connection .
-	AttemptTimeout time.Duration // Timeout for each individual attempt to probe a server's leadership.
-	BackoffFactor  time.Duration // Exponential backoff factor for retries.
-	BackoffCap     time.Duration // Maximum connection retry backoff value,
-	RetryLimit     uint          // Maximum number of retries, or 0 for unlimited.
+	Dial             DialFunc      // Network dialer.
+	DialTimeout      time.Duration // Timeout for establishing a network connection .
+	AttemptTimeout   time.Duration // Timeout for each individual attempt to probe a server's leadership.
+	BackoffFactor    time.Duration // Exponential backoff factor for retries.
+	BackoffCap       time.Duration // Maximum connection retry backoff value,
+	RetryLimit       uint          // Maximum number of retries, or 0 for unlimited.
+	ParallelAttempts int           // Number of servers to probe concurrently, 0 or 1 for one at a time.
+	CallTimeout      time.Duration // Timeout for each call on the leader connection, or 0 for none.
+	Tracer           Tracer        // Traces connection attempts and calls, if not nil.
+	FailureFunc      FailureFunc   // Invoked with all failed attempts when giving up.
+}
+
+// FailureFunc is invoked by Connector.Connect when it gives up trying to find
+// a leader, with the list of all the failed attempts it made.
+type FailureFunc func(attempts []ConnectAttempt)
+
+// ConnectAttempt holds information about a failed attempt to connect to the
+// leader through a single server.
+type ConnectAttempt struct {
+	Address  string        // Address of the server that was tried.
+	Err      error         // Reason for the failure.
+	Duration time.Duration // How long the attempt took.
 }
@@ -64,14 +64,18 @@ func NewConnector(id uint64, store NodeStore, config Config, log logging.Func) *
 // Connect finds the leader server and returns a connection to it.
 //
 // If the connector is stopped before a leader is found, nil is returned.
-func (c *Connector) Connect(ctx context.Context) (*Protocol, error) {
+func (c *Connector) Connect(ctx context.Context) (_ *Protocol, err error) {
 	var protocol *Protocol
+	var attempts []ConnectAttempt
+
+	ctx, end := startSpan(ctx, c.config.Tracer, "dqlite.connect")
+	defer func() { end(err) }()
 
 	strategies := makeRetryStrategies(c.config.BackoffFactor, c.config.BackoffCap, c.config.RetryLimit)
 
 	// The retry strategy should be configured to retry indefinitely, until
 	// the given context is done.
-	err := retry.Retry(func(attempt uint) error {
+	err = retry.Retry(func(attempt uint) error {
 		log := func(l logging.Level, format string, a ...interface{}) {
 			format = fmt.Sprintf("attempt %d: ", attempt) + format
 			c.log(l, format, a...)
@@ -85,7 +89,7 @@ func (c *Connector) Connect(ctx context.Context) (*Protocol, error) {
 		}
 
 		var err error
-		protocol, err = c.connectAttemptAll(ctx, log)
+		protocol, err = c.connectAttemptAll(ctx, log, &attempts)
 		if err != nil {
 			return err
 		}
@@ -96,10 +100,12 @@ func (c *Connector) Connect(ctx context.Context) (*Protocol, error) {
 	if err != nil {
 		// We exhausted the number of retries allowed by the configured
 		// strategy.
+		c.fail(attempts)
 		return nil, ErrNoAvailableLeader
 	}
 
 	if ctx.Err() != nil {
+		c.fail(attempts)
 		return nil, ErrNoAvailableLeader
 	}
 
@@ -110,82 +116,225 @@ func (c *Connector) Connect(ctx context.Context) (*Protocol, error) {
 		panic("no protocol object")
 	}
 
+	protocol.SetCallTimeout(c.config.CallTimeout)
+	protocol.SetTracer(c.config.Tracer)
+
 	return protocol, nil
 }
 
+// Invoke the configured failure function, if any.
+func (c *Connector) fail(attempts []ConnectAttempt) {
+	if c.config.FailureFunc != nil {
+		c.config.FailureFunc(attempts)
+	}
+}
+
 // Make a single attempt to establish a connection to the leader server trying
 // all addresses available in the store.
-func (c *Connector) connectAttemptAll(ctx context.Context, log logging.Func) (*Protocol, error) {
+//
+// Every server that could not be used to reach the leader is appended to the
+// given attempts.
+func (c *Connector) connectAttemptAll(ctx context.Context, log logging.Func, attempts *[]ConnectAttempt) (*Protocol, error) {
 	servers, err := c.store.Get(ctx)
 	if err != nil {
 		return nil, errors.Wrap(err, "get servers")
 	}
 
-	// Sort servers by Role, from low to high.
-	sort.Slice(servers, func(i, j int) bool {
+	// Sort servers by Role, from low to high, trying the cached leader
+	// first if there's one.
+	cache, _ := c.store.(LeaderTracker)
+	cached := ""
+	if cache != nil {
+		cached = cache.Leader()
+	}
+	sort.SliceStable(servers, func(i, j int) bool {
+		if cached != "" && servers[i].Address != servers[j].Address {
+			if servers[i].Address == cached {
+				return true
+			}
+			if servers[j].Address == cached {
+				return false
+			}
+		}
 		return servers[i].Role < servers[j].Role
 	})
 
-	// Make an attempt for each address until we find the leader.
-	for _, server := range servers {
-		log := func(l logging.Level, format string, a ...interface{}) {
-			format = fmt.Sprintf("server %s: ", server.Address) + format
-			log(l, format, a...)
+	var protocol *Protocol
+	var leader string
+	if c.config.ParallelAttempts > 1 {
+		protocol, leader = c.connectAttemptParallel(ctx, log, servers, attempts)
+	} else {
+		// Make an attempt for each address until we find the leader.
+		for _, server := range servers {
+			var failures []ConnectAttempt
+			protocol, leader, failures = c.connectAttemptServer(ctx, log, server.Address)
+			*attempts = append(*attempts, failures...)
+			if protocol != nil {
+				break
+			}
 		}
+	}
 
-		ctx, cancel := context.WithTimeout(ctx, c.config.AttemptTimeout)
-		defer cancel()
+	if cache != nil {
+		cache.SetLeader(leader)
+	}
 
-		version := VersionOne
-		protocol, leader, err := c.connectAttemptOne(ctx, server.Address, version)
-		if err == errBadProtocol {
-			log(logging.Warn, "unsupported protocol %d, attempt with legacy", version)
-			version = VersionLegacy
-			protocol, leader, err = c.connectAttemptOne(ctx, server.Address, version)
-		}
-		if err != nil {
-			// This server is unavailable, try with the next target.
-			log(logging.Warn, err.Error())
-			continue
-		}
-		if protocol != nil {
-			// We found the leader
-			log(logging.Debug, "connected")
-			return protocol, nil
-		}
-		if leader == "" {
-			// This server does not know who the current leader is,
-			// try with the next target.
-			log(logging.Warn, "no known leader")
-			continue
-		}
+	if protocol == nil {
+		return nil, ErrNoAvailableLeader
+	}
 
-		// If we get here, it means this server reported that another
-		// server is the leader, let's close the connection to this
-		// server and try with the suggested one.
-		log(logging.Debug, "connect to reported leader %s", leader)
+	return protocol, nil
+}
 
-		ctx, cancel = context.WithTimeout(ctx, c.config.AttemptTimeout)
-		defer cancel()
+// Probe up to ParallelAttempts servers concurrently, returning the first
+// connection to the leader that is found, along with the leader address, and
+// cancelling the other attempts.
+func (c *Connector) connectAttemptParallel(ctx context.Context, log logging.Func, servers []NodeInfo, attempts *[]ConnectAttempt) (*Protocol, string) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
 
-		protocol, leader, err = c.connectAttemptOne(ctx, leader, version)
-		if err != nil {
-			// The leader reported by the previous server is
-			// unavailable, try with the next target.
-			log(logging.Warn, "reported leader unavailable err=%v", err)
-			continue
-		}
-		if protocol == nil {
-			// The leader reported by the target server does not consider itself
-			// the leader, try with the next target.
-			log(logging.Warn, "reported leader server is not the leader")
+	type result struct {
+		protocol *Protocol
+		leader   string
+		failures []ConnectAttempt
+	}
+
+	// Buffered so attempts never block, even after we stopped waiting.
+	results := make(chan result, len(servers))
+	sem := make(chan struct{}, c.config.ParallelAttempts)
+
+	for _, server := range servers {
+		go func(address string) {
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				results <- result{}
+				return
+			}
+			protocol, leader, failures := c.connectAttemptServer(ctx, log, address)
+			results <- result{protocol: protocol, leader: leader, failures: failures}
+		}(server.Address)
+	}
+
+	for i := range servers {
+		r := <-results
+		*attempts = append(*attempts, r.failures...)
+		if r.protocol == nil {
 			continue
 		}
+
+		// Close any other leader connection established by the
+		// attempts that are still in flight.
+		go func(n int) {
+			for ; n > 0; n-- {
+				if r := <-results; r.protocol != nil {
+					r.protocol.Close()
+				}
+			}
+		}(len(servers) - i - 1)
+
+		return r.protocol, r.leader
+	}
+
+	return nil, ""
+}
+
+// Try to reach the leader through the server with the given address, following
+// its redirect if it reports another server as leader.
+//
+// Return the connection to the leader and its address, if found, along with
+// all the failed attempts.
+func (c *Connector) connectAttemptServer(ctx context.Context, parentLog logging.Func, address string) (*Protocol, string, []ConnectAttempt) {
+	var failures []ConnectAttempt
+
+	log := func(l logging.Level, format string, a ...interface{}) {
+		format = fmt.Sprintf("server %s: ", address) + format
+		parentLog(l, format, a...)
+	}
+
+	start := time.Now()
+	ctx, end := startSpan(ctx, c.config.Tracer, "dqlite.connect.attempt", "address", address)
+	failed := func(address string, err error) {
+		failures = append(failures, ConnectAttempt{
+			Address:  address,
+			Err:      err,
+			Duration: time.Since(start),
+		})
+		end(err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.config.AttemptTimeout)
+	defer cancel()
+
+	version := VersionOne
+	protocol, leader, err := c.connectAttemptOne(ctx, address, version)
+	if err == errBadProtocol {
+		log(logging.Warn, "unsupported protocol %d, attempt with legacy", version)
+		version = VersionLegacy
+		protocol, leader, err = c.connectAttemptOne(ctx, address, version)
+	}
+	if err != nil {
+		// This server is unavailable, try with the next target.
+		log(logging.Warn, err.Error())
+		failed(address, err)
+		return nil, "", failures
+	}
+	if protocol != nil {
+		// We found the leader
 		log(logging.Debug, "connected")
-		return protocol, nil
+		end(nil)
+		return protocol, address, failures
 	}
+	if leader == "" {
+		// This server does not know who the current leader is,
+		// try with the next target.
+		log(logging.Warn, "no known leader")
+		failed(address, errNoKnownLeader)
+		return nil, "", failures
+	}
+
+	// If we get here, it means this server reported that another
+	// server is the leader, let's close the connection to this
+	// server and try with the suggested one.
+	log(logging.Debug, "connect to reported leader %s", leader)
+
+	ctx, cancel = context.WithTimeout(ctx, c.config.AttemptTimeout)
+	defer cancel()
 
-	return nil, ErrNoAvailableLeader
+	reported := leader
+	start = time.Now()
+
+	// From now on failures are recorded in the redirect span, which is
+	// nested in the attempt one.
+	attemptEnd := end
+	defer func() {
+		var err error
+		if len(failures) > 0 {
+			err = failures[len(failures)-1].Err
+		}
+		attemptEnd(err)
+	}()
+	ctx, end = startSpan(ctx, c.config.Tracer, "dqlite.connect.redirect", "address", reported, "from", address)
+
+	protocol, _, err = c.connectAttemptOne(ctx, reported, version)
+	if err != nil {
+		// The leader reported by the previous server is
+		// unavailable, try with the next target.
+		log(logging.Warn, "reported leader unavailable err=%v", err)
+		failed(reported, err)
+		return nil, "", failures
+	}
+	if protocol == nil {
+		// The leader reported by the target server does not consider itself
+		// the leader, try with the next target.
+		log(logging.Warn, "reported leader server is not the leader")
+		failed(reported, errStaleLeader)
+		return nil, "", failures
+	}
+	log(logging.Debug, "connected")
+	end(nil)
+	return protocol, reported, failures
 }
 
 // Perform the initial handshake using the given protocol version.
@@ -2,7 +2,9 @@ package protocol_test
 
 import (
 	"context"
+	"encoding/binary"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net"
 	"os"
@@ -59,6 +61,191 @@ func TestConnector_LimitRetries(t *testing.T) {
 	})
 }
 
+// When giving up, the failure function receives all failed attempts.
+func TestConnector_FailureFunc(t *testing.T) {
+	store := newStore(t, []string{"@test-123", "@test-456"})
+
+	var attempts []protocol.ConnectAttempt
+	config := protocol.Config{
+		RetryLimit: 1,
+		FailureFunc: func(a []protocol.ConnectAttempt) {
+			attempts = a
+		},
+	}
+	connector := protocol.NewConnector(0, store, config, logging.Test(t))
+
+	_, err := connector.Connect(context.Background())
+	assert.Equal(t, protocol.ErrNoAvailableLeader, err)
+
+	require.Len(t, attempts, 4)
+	for i, address := range []string{"@test-123", "@test-456", "@test-123", "@test-456"} {
+		assert.Equal(t, address, attempts[i].Address)
+		assert.EqualError(t, attempts[i].Err, fmt.Sprintf("dial: dial unix %s: connect: connection refused", address))
+	}
+}
+
+// Servers can be probed concurrently, skipping dead ones.
+func TestConnector_ParallelAttempts(t *testing.T) {
+	address, cleanup := newNode(t, 0)
+	defer cleanup()
+
+	store := newStore(t, []string{"@test-123", address})
+
+	config := protocol.Config{
+		ParallelAttempts: 2,
+	}
+	connector := protocol.NewConnector(0, store, config, logging.Test(t))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	client, err := connector.Connect(ctx)
+	require.NoError(t, err)
+
+	assert.NoError(t, client.Close())
+}
+
+// When probing servers concurrently, all failed attempts are reported.
+func TestConnector_ParallelAttemptsFailure(t *testing.T) {
+	store := newStore(t, []string{"@test-123", "@test-456", "@test-789"})
+
+	var attempts []protocol.ConnectAttempt
+	config := protocol.Config{
+		RetryLimit:       1,
+		ParallelAttempts: 2,
+		FailureFunc: func(a []protocol.ConnectAttempt) {
+			attempts = a
+		},
+	}
+	connector := protocol.NewConnector(0, store, config, logging.Test(t))
+
+	_, err := connector.Connect(context.Background())
+	assert.Equal(t, protocol.ErrNoAvailableLeader, err)
+
+	require.Len(t, attempts, 6)
+	addresses := map[string]int{}
+	for _, attempt := range attempts {
+		addresses[attempt.Address]++
+		assert.Error(t, attempt.Err)
+	}
+	assert.Equal(t, map[string]int{"@test-123": 2, "@test-456": 2, "@test-789": 2}, addresses)
+}
+
+// The cached leader is tried first, and the cache is cleared if no leader is
+// found.
+func TestConnector_LeaderCache(t *testing.T) {
+	store := protocol.NewLeaderCacheStore(newStore(t, []string{"@test-123", "@test-456"}))
+	store.SetLeader("@test-456")
+
+	var attempts []protocol.ConnectAttempt
+	config := protocol.Config{
+		RetryLimit: 1,
+		FailureFunc: func(a []protocol.ConnectAttempt) {
+			attempts = a
+		},
+	}
+	connector := protocol.NewConnector(0, store, config, logging.Test(t))
+
+	_, err := connector.Connect(context.Background())
+	assert.Equal(t, protocol.ErrNoAvailableLeader, err)
+
+	require.Len(t, attempts, 4)
+	assert.Equal(t, "@test-456", attempts[0].Address)
+	assert.Equal(t, "@test-123", attempts[1].Address)
+	assert.Equal(t, "@test-123", attempts[2].Address)
+	assert.Equal(t, "@test-456", attempts[3].Address)
+
+	assert.Equal(t, "", store.Leader())
+}
+
+// Any store implementing LeaderTracker gets the leader cached, not only
+// LeaderCacheStore.
+func TestConnector_LeaderTracker(t *testing.T) {
+	store := &wrappedStore{protocol.NewLeaderCacheStore(newStore(t, []string{"@test-123", "@test-456"}))}
+	store.SetLeader("@test-456")
+
+	var attempts []protocol.ConnectAttempt
+	config := protocol.Config{
+		RetryLimit: 1,
+		FailureFunc: func(a []protocol.ConnectAttempt) {
+			attempts = a
+		},
+	}
+	connector := protocol.NewConnector(0, store, config, logging.Test(t))
+
+	_, err := connector.Connect(context.Background())
+	assert.Equal(t, protocol.ErrNoAvailableLeader, err)
+
+	require.Len(t, attempts, 4)
+	assert.Equal(t, "@test-456", attempts[0].Address)
+	assert.Equal(t, "@test-123", attempts[1].Address)
+	assert.Equal(t, "", store.Leader())
+}
+
+// Store wrapping another one, as applications commonly do.
+type wrappedStore struct {
+	*protocol.LeaderCacheStore
+}
+
+// Connection attempts are traced.
+func TestConnector_Tracer(t *testing.T) {
+	store := newStore(t, []string{"@test-123"})
+
+	tracer := &testTracer{}
+	config := protocol.Config{
+		RetryLimit: 1,
+		Tracer:     tracer,
+	}
+	connector := protocol.NewConnector(0, store, config, logging.Test(t))
+
+	_, err := connector.Connect(context.Background())
+	assert.Equal(t, protocol.ErrNoAvailableLeader, err)
+
+	assert.Equal(t, []string{
+		"dqlite.connect.attempt [address @test-123]: dial: dial unix @test-123: connect: connection refused",
+		"dqlite.connect.attempt [address @test-123]: dial: dial unix @test-123: connect: connection refused",
+		"dqlite.connect []: no available dqlite leader server found",
+	}, tracer.spans)
+}
+
+// When the leader reported by a server can't be reached, both the redirect
+// span and the enclosing attempt span end with the failure.
+func TestConnector_TracerRedirectFailure(t *testing.T) {
+	address, cleanup := newRedirectingServer(t, "@test-redirect-to")
+	defer cleanup()
+	store := newStore(t, []string{address})
+
+	tracer := &testTracer{}
+	config := protocol.Config{
+		RetryLimit: 1,
+		Tracer:     tracer,
+	}
+	connector := protocol.NewConnector(0, store, config, logging.Test(t))
+
+	_, err := connector.Connect(context.Background())
+	assert.Equal(t, protocol.ErrNoAvailableLeader, err)
+
+	require.True(t, len(tracer.spans) >= 2)
+	failure := "dial: dial unix @test-redirect-to: connect: connection refused"
+	assert.Equal(t, "dqlite.connect.redirect [address @test-redirect-to from @test-redirect-from]: "+failure, tracer.spans[0])
+	assert.Equal(t, "dqlite.connect.attempt [address @test-redirect-from]: "+failure, tracer.spans[1])
+}
+
+// Tracer recording ended spans.
+type testTracer struct {
+	spans []string
+}
+
+func (t *testTracer) Start(ctx context.Context, name string, attrs ...string) (context.Context, func(error)) {
+	return ctx, func(err error) {
+		span := fmt.Sprintf("%s %v", name, attrs)
+		if err != nil {
+			span += ": " + err.Error()
+		}
+		t.spans = append(t.spans, span)
+	}
+}
+
 // The network connection can't be established because of a connection timeout.
 func TestConnector_DialTimeout(t *testing.T) {
 	store := newStore(t, []string{"8.8.8.8:9000"})
@@ -324,6 +511,46 @@ func newStore(t *testing.T, addresses []string) protocol.NodeStore {
 	return store
 }
 
+// Start a fake server at @test-redirect-from which replies to every Leader
+// request by reporting the given address as leader.
+func newRedirectingServer(t *testing.T, leader string) (string, func()) {
+	t.Helper()
+
+	listener, err := net.Listen("unix", "@test-redirect-from")
+	require.NoError(t, err)
+
+	// Node response body: the leader ID and its address, as a string
+	// padded to a word boundary.
+	name := make([]byte, (len(leader)/8+1)*8)
+	copy(name, leader)
+	body := make([]byte, 8+len(name))
+	binary.LittleEndian.PutUint64(body, 1)
+	copy(body[8:], name)
+
+	header := make([]byte, 8)
+	binary.LittleEndian.PutUint32(header, uint32(len(body)/8))
+	header[4] = protocol.ResponseNode
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			// Consume the handshake and the request, then reply.
+			request := make([]byte, 16)
+			if _, err := io.ReadFull(conn, request); err == nil {
+				words := binary.LittleEndian.Uint32(request[8:])
+				io.CopyN(ioutil.Discard, conn, int64(words)*8)
+				conn.Write(append(header, body...))
+			}
+			conn.Close()
+		}
+	}()
+
+	return listener.Addr().String(), func() { listener.Close() }
+}
+
 func newNode(t *testing.T, index int) (string, func()) {
 	t.Helper()
 
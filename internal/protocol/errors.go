@@ -10,10 +10,25 @@ var (
 	errStop              = fmt.Errorf("connector was stopped")
 	errStaleLeader       = fmt.Errorf("server has lost leadership")
 	errNotClustered      = fmt.Errorf("server is not clustered")
+	errNoKnownLeader     = fmt.Errorf("no known leader")
 	errNegativeRead      = fmt.Errorf("reader returned negative count from Read")
 	errMessageEOF        = fmt.Errorf("message eof")
 )
 
+// ErrNotLeader is matched by request failures caused by the target server not
+// being the leader, or having lost leadership while serving the request.
+var ErrNotLeader = fmt.Errorf("server is not the leader")
+
+// Error codes for leadership failures, including the legacy ones used before
+// version-3.32.1+replication4.
+const (
+	ErrIoErr                     = 10
+	ErrIoErrNotLeader            = ErrIoErr | (40 << 8)
+	ErrIoErrLeadershipLost       = ErrIoErr | (41 << 8)
+	ErrIoErrNotLeaderLegacy      = ErrIoErr | (32 << 8)
+	ErrIoErrLeadershipLostLegacy = ErrIoErr | (33 << 8)
+)
+
 // ErrRequest is returned in case of request failure.
 type ErrRequest struct {
 	Code        uint64
@@ -24,6 +39,19 @@ func (e ErrRequest) Error() string {
 	return fmt.Sprintf("%s (%d)", e.Description, e.Code)
 }
 
+// Is makes errors.Is(err, ErrNotLeader) report whether the request failed
+// because of leadership.
+func (e ErrRequest) Is(target error) bool {
+	if target != ErrNotLeader {
+		return false
+	}
+	switch e.Code {
+	case ErrIoErrNotLeader, ErrIoErrLeadershipLost, ErrIoErrNotLeaderLegacy, ErrIoErrLeadershipLostLegacy:
+		return true
+	}
+	return false
+}
+
 // ErrRowsPart is returned when the first batch of a multi-response result
 // batch is done.
 var ErrRowsPart = fmt.Errorf("not all rows were returned in this response")
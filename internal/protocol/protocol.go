@@ -18,6 +18,8 @@ type Protocol struct {
 	closeCh chan struct{} // Stops the heartbeat when the connection gets closed
 	mu      sync.Mutex    // Serialize requests
 	netErr  error         // A network error occurred
+	timeout time.Duration // Timeout for each call, if not zero
+	tracer  Tracer        // Traces calls, if not nil
 }
 
 func newProtocol(version uint64, conn net.Conn) *Protocol {
@@ -30,14 +32,39 @@ func newProtocol(version uint64, conn net.Conn) *Protocol {
 	return protocol
 }
 
+// SetCallTimeout sets a timeout that bounds each individual Call, regardless
+// of the deadline of the context passed to it. Zero means no timeout.
+func (p *Protocol) SetCallTimeout(timeout time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.timeout = timeout
+}
+
+// SetTracer sets a tracer that gets a "dqlite.call" span for each Call.
+func (p *Protocol) SetTracer(tracer Tracer) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.tracer = tracer
+}
+
 // Call invokes a dqlite RPC, sending a request message and receiving a
 // response message.
+//
+// If the call doesn't complete within the call timeout or the context
+// deadline, the returned error has a net.Error cause whose Timeout method
+// returns true, and the connection can't be used anymore.
 func (p *Protocol) Call(ctx context.Context, request, response *Message) (err error) {
 	// We need to take a lock since the dqlite server currently does not
 	// support concurrent requests.
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
+	if p.timeout != 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.timeout)
+		defer cancel()
+	}
+
 	if p.netErr != nil {
 		return p.netErr
 	}
@@ -63,6 +90,11 @@ func (p *Protocol) Call(ctx context.Context, request, response *Message) (err er
 
 	desc := requestDesc(request.mtype)
 
+	if p.tracer != nil {
+		_, end := p.tracer.Start(ctx, "dqlite.call", "request", desc, "address", p.conn.RemoteAddr().String())
+		defer func() { end(err) }()
+	}
+
 	if err = p.send(request); err != nil {
 		return errors.Wrapf(err, "call %s (budget %s): send", desc, budget)
 	}
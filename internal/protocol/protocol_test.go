@@ -2,11 +2,15 @@ package protocol_test
 
 import (
 	"context"
+	"io"
+	"io/ioutil"
+	"net"
 	"testing"
 	"time"
 
 	"github.com/canonical/go-dqlite/internal/protocol"
 	"github.com/canonical/go-dqlite/logging"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -56,6 +60,42 @@ CREATE TABLE baz (n INT);
 	makeCall(t, p, &request, &response)
 }
 
+// A call that doesn't get a response within the call timeout fails.
+func TestProtocol_CallTimeout(t *testing.T) {
+	client, server := net.Pipe()
+	defer server.Close()
+
+	// Swallow everything without ever responding.
+	go io.Copy(ioutil.Discard, server)
+
+	p, err := protocol.Handshake(context.Background(), client, protocol.VersionOne)
+	require.NoError(t, err)
+	defer p.Close()
+
+	p.SetCallTimeout(50 * time.Millisecond)
+
+	request, response := newMessagePair(64, 64)
+	protocol.EncodeLeader(&request)
+
+	err = p.Call(context.Background(), &request, &response)
+	require.Error(t, err)
+
+	cause, ok := errors.Cause(err).(net.Error)
+	require.True(t, ok)
+	assert.True(t, cause.Timeout())
+}
+
+// Leadership failures match ErrNotLeader, even when wrapped.
+func TestErrRequest_IsNotLeader(t *testing.T) {
+	for _, code := range []uint64{10 | (40 << 8), 10 | (41 << 8), 10 | (32 << 8), 10 | (33 << 8)} {
+		err := errors.Wrap(protocol.ErrRequest{Code: code, Description: "not leader"}, "failed")
+		assert.True(t, errors.Is(err, protocol.ErrNotLeader))
+	}
+
+	err := errors.Wrap(protocol.ErrRequest{Code: 1, Description: "error"}, "failed")
+	assert.False(t, errors.Is(err, protocol.ErrNotLeader))
+}
+
 func TestProtocol_Prepare(t *testing.T) {
 	c, cleanup := newProtocol(t)
 	defer cleanup()
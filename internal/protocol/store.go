@@ -74,3 +74,47 @@ func (i *InmemNodeStore) Set(ctx context.Context, servers []NodeInfo) error {
 	i.servers = servers
 	return nil
 }
+
+// LeaderTracker can be implemented by a NodeStore to remember the address of
+// the last leader that a Connector reached through it.
+//
+// The Connector tries the tracked leader before any other server, which saves
+// probing the cluster again when the leader hasn't changed, and sets it to an
+// empty string when no leader could be found.
+type LeaderTracker interface {
+	// Leader returns the address of the tracked leader, or an empty
+	// string if there's none.
+	Leader() string
+
+	// SetLeader sets the address of the tracked leader.
+	SetLeader(address string)
+}
+
+// LeaderCacheStore wraps a NodeStore and implements LeaderTracker by caching
+// the leader address in memory.
+type LeaderCacheStore struct {
+	NodeStore
+	mu     sync.RWMutex
+	leader string
+}
+
+// NewLeaderCacheStore creates a LeaderCacheStore wrapping the given store.
+func NewLeaderCacheStore(inner NodeStore) *LeaderCacheStore {
+	return &LeaderCacheStore{NodeStore: inner}
+}
+
+// Leader returns the address of the cached leader, or an empty string if
+// there's none.
+func (s *LeaderCacheStore) Leader() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.leader
+}
+
+// SetLeader sets the address of the cached leader. An empty address clears
+// the cache.
+func (s *LeaderCacheStore) SetLeader(address string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.leader = address
+}
@@ -0,0 +1,28 @@
+package protocol
+
+import (
+	"context"
+)
+
+// Tracer can be used to trace the operations performed by a Connector and by
+// the Protocol objects it returns, for example with OpenTelemetry.
+//
+// Spans are named "dqlite.connect" for a whole Connect call,
+// "dqlite.connect.attempt" for each attempt to reach the leader through a
+// server and "dqlite.connect.redirect" for each attempt to connect to the
+// leader that a server reported, and "dqlite.call" for each request.
+type Tracer interface {
+	// Start a new span with the given name and attributes, which are
+	// given as a flat list of key/value pairs. The returned function must
+	// be called to end the span, with the error the operation failed with,
+	// if any.
+	Start(ctx context.Context, name string, attrs ...string) (context.Context, func(err error))
+}
+
+// Start a span with the given tracer, which may be nil.
+func startSpan(ctx context.Context, tracer Tracer, name string, attrs ...string) (context.Context, func(error)) {
+	if tracer == nil {
+		return ctx, func(error) {}
+	}
+	return tracer.Start(ctx, name, attrs...)
+}
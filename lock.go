@@ -0,0 +1,40 @@
+package dqlite
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+
+	"github.com/pkg/errors"
+)
+
+// ErrDirectoryLocked is returned by New if the given data directory is already
+// in use by another node.
+var ErrDirectoryLocked = fmt.Errorf("data directory is locked by another node")
+
+// Name of the lock file created in the data directory.
+const lockFile = "dqlite.lock"
+
+// Take an exclusive lock on the given data directory.
+//
+// The lock is released when the returned file is closed, or automatically by
+// the kernel if the process dies.
+func lockDir(dir string) (*os.File, error) {
+	path := filepath.Join(dir, lockFile)
+
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, errors.Wrap(err, "open lock file")
+	}
+
+	if err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		file.Close()
+		if err == syscall.EWOULDBLOCK {
+			return nil, ErrDirectoryLocked
+		}
+		return nil, errors.Wrap(err, "lock data directory")
+	}
+
+	return file, nil
+}
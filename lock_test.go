@@ -0,0 +1,25 @@
+package dqlite
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockDir(t *testing.T) {
+	dir, cleanup := newDir(t)
+	defer cleanup()
+
+	lock, err := lockDir(dir)
+	require.NoError(t, err)
+
+	_, err = lockDir(dir)
+	assert.Equal(t, ErrDirectoryLocked, err)
+
+	require.NoError(t, lock.Close())
+
+	lock, err = lockDir(dir)
+	require.NoError(t, err)
+	require.NoError(t, lock.Close())
+}
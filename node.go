@@ -2,6 +2,9 @@ package dqlite
 
 import (
 	"context"
+	"fmt"
+	"net"
+	"os"
 	"time"
 
 	"github.com/canonical/go-dqlite/client"
@@ -17,6 +20,10 @@ type Node struct {
 	id          uint64
 	address     string
 	bindAddress string
+	dir         string
+	lock        *os.File // Lock on the data directory
+	started     bool
+	closed      bool // The data directory lock was released by Close
 	cancel      context.CancelFunc
 }
 
@@ -41,6 +48,20 @@ func WithDialFunc(dial client.DialFunc) Option {
 	}
 }
 
+// WithAddressResolver sets a function that rewrites the address of a peer
+// before dialing it.
+//
+// The function receives the address the peer advertised to the cluster and
+// returns the address that should be used to reach it from this node. This is
+// useful when nodes live in different network zones (e.g. behind NAT) and are
+// reachable through zone-specific addresses. The resolver is applied on top of
+// the dial function set with WithDialFunc, if any.
+func WithAddressResolver(resolve func(advertised string) string) Option {
+	return func(options *options) {
+		options.AddressResolver = resolve
+	}
+}
+
 // WithBindAddress sets a custom bind address for the server.
 func WithBindAddress(address string) Option {
 	return func(options *options) {
@@ -69,6 +90,23 @@ func WithSnapshotParams(params SnapshotParams) Option {
 	}
 }
 
+// WithSnapshotCompression enables or disables LZ4 compression of the
+// snapshots taken by the node.
+//
+// Snapshots are sent to other nodes in the same form they're stored, so
+// compression reduces both disk usage and the bandwidth used to bring new or
+// lagging nodes up to date, at the cost of some CPU time when taking and
+// installing snapshots. Nodes can install snapshots in either form, so nodes
+// with different settings can be mixed in a cluster.
+//
+// If not used, the default of the linked dqlite library applies, which is to
+// compress snapshots if it was built with LZ4 support.
+func WithSnapshotCompression(enabled bool) Option {
+	return func(options *options) {
+		options.SnapshotCompression = &enabled
+	}
+}
+
 // WithDiskMode enables dqlite disk-mode on the node.
 // WARNING: This is experimental API, use with caution
 // and prepare for data loss.
@@ -81,53 +119,82 @@ func WithDiskMode(disk bool) Option {
 }
 
 // New creates a new Node instance.
-func New(id uint64, address string, dir string, options ...Option) (*Node, error) {
+//
+// The node takes an exclusive lock on the given data directory, which is
+// released when the node is closed. If another node (possibly in another
+// process) is already using the directory, ErrDirectoryLocked is returned.
+//
+// The ID must not be zero, and must be unique within the cluster: see
+// GenerateID and BootstrapID.
+func New(id uint64, address string, dir string, options ...Option) (_ *Node, err error) {
+	if id == 0 {
+		return nil, fmt.Errorf("node ID must not be zero")
+	}
+
 	o := defaultOptions()
 
 	for _, option := range options {
 		option(o)
 	}
 
+	lock, err := lockDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			lock.Close()
+		}
+	}()
+
 	ctx, cancel := context.WithCancel(context.Background())
 	server, err := bindings.NewNode(ctx, id, address, dir)
 	if err != nil {
 		cancel()
 		return nil, err
 	}
+	defer func() {
+		if err != nil {
+			server.Close()
+			cancel()
+		}
+	}()
 
+	if o.AddressResolver != nil {
+		o.DialFunc = resolvingDialFunc(o.DialFunc, o.AddressResolver)
+	}
 	if o.DialFunc != nil {
 		if err := server.SetDialFunc(o.DialFunc); err != nil {
-			cancel()
 			return nil, err
 		}
 	}
 	if o.BindAddress != "" {
 		if err := server.SetBindAddress(o.BindAddress); err != nil {
-			cancel()
 			return nil, err
 		}
 	}
 	if o.NetworkLatency != 0 {
 		if err := server.SetNetworkLatency(o.NetworkLatency); err != nil {
-			cancel()
 			return nil, err
 		}
 	}
 	if o.FailureDomain != 0 {
 		if err := server.SetFailureDomain(o.FailureDomain); err != nil {
-			cancel()
 			return nil, err
 		}
 	}
 	if o.SnapshotParams.Threshold != 0 || o.SnapshotParams.Trailing != 0 {
 		if err := server.SetSnapshotParams(o.SnapshotParams); err != nil {
-			cancel()
+			return nil, err
+		}
+	}
+	if o.SnapshotCompression != nil {
+		if err := server.SetSnapshotCompression(*o.SnapshotCompression); err != nil {
 			return nil, err
 		}
 	}
 	if o.DiskMode {
 		if err := server.EnableDiskMode(); err != nil {
-			cancel()
 			return nil, err
 		}
 	}
@@ -138,6 +205,8 @@ func New(id uint64, address string, dir string, options ...Option) (*Node, error
 		id:          id,
 		address:     address,
 		bindAddress: o.BindAddress,
+		dir:         dir,
+		lock:        lock,
 		cancel:      cancel,
 	}
 
@@ -149,9 +218,18 @@ func (s *Node) BindAddress() string {
 	return s.server.GetBindAddress()
 }
 
+// Dir returns the data directory of the node, as passed to New().
+func (s *Node) Dir() string {
+	return s.dir
+}
+
 // Start serving requests.
 func (s *Node) Start() error {
-	return s.server.Start()
+	if err := s.server.Start(); err != nil {
+		return err
+	}
+	s.started = true
+	return nil
 }
 
 // Recover a node by forcing a new cluster configuration.
@@ -164,17 +242,28 @@ func (s *Node) Recover(cluster []NodeInfo) error {
 
 // Hold configuration options for a dqlite server.
 type options struct {
-	Log            client.LogFunc
-	DialFunc       client.DialFunc
-	BindAddress    string
-	NetworkLatency uint64
-	FailureDomain  uint64
-	SnapshotParams bindings.SnapshotParams
-	DiskMode       bool
+	Log                 client.LogFunc
+	DialFunc            client.DialFunc
+	AddressResolver     func(string) string
+	BindAddress         string
+	NetworkLatency      uint64
+	FailureDomain       uint64
+	SnapshotParams      bindings.SnapshotParams
+	SnapshotCompression *bool // Use the library default if nil.
+	DiskMode            bool
 }
 
 // Close the server, releasing all resources it created.
-func (s *Node) Close() error {
+//
+// The data directory lock is released even if the server fails to stop.
+func (s *Node) Close() (err error) {
+	defer func() {
+		s.closed = true
+		if lerr := s.lock.Close(); lerr != nil && err == nil {
+			err = errors.Wrap(lerr, "release data directory lock")
+		}
+	}()
+
 	s.cancel()
 	// Send a stop signal to the dqlite event loop.
 	if err := s.server.Stop(); err != nil {
@@ -182,6 +271,7 @@ func (s *Node) Close() error {
 	}
 
 	s.server.Close()
+	s.started = false
 
 	return nil
 }
@@ -192,6 +282,11 @@ const BootstrapID = 0x2dc171858c3155be
 
 // GenerateID generates a unique ID for a new node, based on a hash of its
 // address and the current time.
+//
+// Since the current time is hashed too, the ID isn't stable: it should be
+// generated once and persisted along with the node's data. IDs are 64-bit
+// hash values, so the probability of a collision in a cluster of n nodes is
+// roughly n²/2⁶⁵, which is negligible in practice.
 func GenerateID(address string) uint64 {
 	return bindings.GenerateID(address)
 }
@@ -201,7 +296,17 @@ func GenerateID(address string) uint64 {
 //
 // It forces appending a new configuration to the raft log stored in the given
 // directory, effectively replacing the current configuration.
+//
+// This is a last-resort operation that can lose committed data if used
+// carelessly, and it must be run while no node is using the directory:
+// ErrDirectoryLocked is returned otherwise.
 func ReconfigureMembership(dir string, cluster []NodeInfo) error {
+	lock, err := lockDir(dir)
+	if err != nil {
+		return err
+	}
+	defer lock.Close()
+
 	server, err := bindings.NewNode(context.Background(), 1, "1", dir)
 	if err != nil {
 		return err
@@ -218,7 +323,16 @@ func ReconfigureMembership(dir string, cluster []NodeInfo) error {
 // In comparision with ReconfigureMembership, this function takes the node role
 // into account and makes use of a dqlite API that supports extending the
 // NodeInfo struct.
+//
+// Like ReconfigureMembership, it returns ErrDirectoryLocked if a node is using
+// the directory.
 func ReconfigureMembershipExt(dir string, cluster []NodeInfo) error {
+	lock, err := lockDir(dir)
+	if err != nil {
+		return err
+	}
+	defer lock.Close()
+
 	server, err := bindings.NewNode(context.Background(), 1, "1", dir)
 	if err != nil {
 		return err
@@ -227,6 +341,16 @@ func ReconfigureMembershipExt(dir string, cluster []NodeInfo) error {
 	return server.RecoverExt(cluster)
 }
 
+// Wrap the given dial function so it dials the address returned by resolve.
+func resolvingDialFunc(dial client.DialFunc, resolve func(string) string) client.DialFunc {
+	if dial == nil {
+		dial = client.DefaultDialFunc
+	}
+	return func(ctx context.Context, address string) (net.Conn, error) {
+		return dial(ctx, resolve(address))
+	}
+}
+
 // Create a options object with sane defaults.
 func defaultOptions() *options {
 	return &options{
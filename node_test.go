@@ -0,0 +1,104 @@
+package dqlite
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// If New fails after the low-level node was created, all resources are
+// released.
+func TestNew_ErrorReleasesResources(t *testing.T) {
+	dir, cleanup := newDir(t)
+	defer cleanup()
+
+	address := "@dqlite-new-error-test"
+
+	// Setting the bind address succeeds, but the network latency is
+	// rejected for being greater than one hour.
+	_, err := New(1, address, dir, WithBindAddress(address), WithNetworkLatency(2*time.Hour))
+	assert.Error(t, err)
+
+	// Both the data directory and the bind address are available again.
+	node, err := New(1, address, dir, WithBindAddress(address))
+	require.NoError(t, err)
+	require.NoError(t, node.Start())
+	require.NoError(t, node.Close())
+}
+
+// The data directory stays locked while the node is alive, and can be reused
+// once it's closed.
+func TestNew_DirectoryLocked(t *testing.T) {
+	dir, cleanup := newDir(t)
+	defer cleanup()
+
+	address := "@dqlite-directory-locked-test"
+
+	node, err := New(1, address, dir, WithBindAddress(address))
+	require.NoError(t, err)
+	require.NoError(t, node.Start())
+
+	_, err = New(1, address, dir, WithBindAddress(address))
+	assert.Equal(t, ErrDirectoryLocked, err)
+
+	require.NoError(t, node.Close())
+
+	node, err = New(1, address, dir, WithBindAddress(address))
+	require.NoError(t, err)
+	require.NoError(t, node.Start())
+	require.NoError(t, node.Close())
+}
+
+// The address resolver rewrites addresses before they are dialed.
+func TestResolvingDialFunc(t *testing.T) {
+	var dialed string
+	dial := func(ctx context.Context, address string) (net.Conn, error) {
+		dialed = address
+		return nil, fmt.Errorf("boom")
+	}
+	resolve := func(advertised string) string {
+		return "10.0.0.1:9001"
+	}
+
+	_, err := resolvingDialFunc(dial, resolve)(context.Background(), "192.168.1.1:9001")
+	assert.EqualError(t, err, "boom")
+	assert.Equal(t, "10.0.0.1:9001", dialed)
+}
+
+// Membership can't be reconfigured while the directory is in use.
+func TestReconfigureMembership_DirectoryLocked(t *testing.T) {
+	dir, cleanup := newDir(t)
+	defer cleanup()
+
+	lock, err := lockDir(dir)
+	require.NoError(t, err)
+	defer lock.Close()
+
+	cluster := []NodeInfo{{ID: 1, Address: "@1"}}
+	assert.Equal(t, ErrDirectoryLocked, ReconfigureMembership(dir, cluster))
+	assert.Equal(t, ErrDirectoryLocked, ReconfigureMembershipExt(dir, cluster))
+}
+
+func TestNew_ZeroID(t *testing.T) {
+	dir, cleanup := newDir(t)
+	defer cleanup()
+
+	_, err := New(0, "@dqlite-zero-id-test", dir)
+	assert.EqualError(t, err, "node ID must not be zero")
+}
+
+func TestNew_SnapshotCompression(t *testing.T) {
+	dir, cleanup := newDir(t)
+	defer cleanup()
+
+	address := "@dqlite-snapshot-compression-test"
+	node, err := New(1, address, dir, WithBindAddress(address), WithSnapshotCompression(false))
+	require.NoError(t, err)
+	require.NoError(t, node.Start())
+	require.NoError(t, node.Close())
+}
@@ -0,0 +1,97 @@
+package dqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/canonical/go-dqlite/client"
+	"github.com/canonical/go-dqlite/driver"
+	"github.com/pkg/errors"
+)
+
+// SelfTest checks that a node can form a single-node cluster using the given
+// data directory, and that the cluster can serve a write and a read.
+//
+// The node runs in a temporary subdirectory of the given one, which is removed
+// before returning, so the content of the directory itself is left untouched.
+// The node binds to a private abstract Unix socket and is shut down before
+// returning. Any failure is returned as an error.
+func SelfTest(dir string) (err error) {
+	address := fmt.Sprintf("@dqlite-selftest-%d", os.Getpid())
+
+	tmp, err := ioutil.TempDir(dir, "dqlite-selftest-")
+	if err != nil {
+		return errors.Wrap(err, "create temporary directory")
+	}
+	defer func() {
+		if rerr := os.RemoveAll(tmp); rerr != nil && err == nil {
+			err = errors.Wrap(rerr, "remove temporary directory")
+		}
+	}()
+
+	node, err := New(1, address, tmp, WithBindAddress(address))
+	if err != nil {
+		return errors.Wrap(err, "create node")
+	}
+	defer func() {
+		if cerr := node.Close(); cerr != nil && err == nil {
+			err = errors.Wrap(cerr, "close node")
+		}
+	}()
+
+	if err := node.Start(); err != nil {
+		return errors.Wrap(err, "start node")
+	}
+
+	store := client.NewInmemNodeStore()
+	if err := store.Set(context.Background(), []client.NodeInfo{{ID: 1, Address: address}}); err != nil {
+		return errors.Wrap(err, "set node store")
+	}
+
+	drv, err := driver.New(store, driver.WithRetryLimit(10), driver.WithContextTimeout(5*time.Second))
+	if err != nil {
+		return errors.Wrap(err, "create driver")
+	}
+	connector, err := drv.OpenConnector("selftest.db")
+	if err != nil {
+		return errors.Wrap(err, "open connector")
+	}
+	db := sql.OpenDB(connector)
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := db.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS selftest (n INT)"); err != nil {
+		return errors.Wrap(err, "create table")
+	}
+	if _, err := db.ExecContext(ctx, "INSERT INTO selftest(n) VALUES(?)", 123); err != nil {
+		return errors.Wrap(err, "insert row")
+	}
+
+	var n int
+	if err := db.QueryRowContext(ctx, "SELECT n FROM selftest ORDER BY rowid DESC LIMIT 1").Scan(&n); err != nil {
+		return errors.Wrap(err, "query row")
+	}
+	if n != 123 {
+		return fmt.Errorf("read back %d instead of 123", n)
+	}
+
+	var result string
+	if err := db.QueryRowContext(ctx, "PRAGMA integrity_check").Scan(&result); err != nil {
+		return errors.Wrap(err, "check integrity")
+	}
+	if result != "ok" {
+		return fmt.Errorf("integrity check failed: %s", result)
+	}
+
+	if err := db.Close(); err != nil {
+		return errors.Wrap(err, "close database")
+	}
+
+	return nil
+}
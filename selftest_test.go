@@ -0,0 +1,28 @@
+package dqlite
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelfTest(t *testing.T) {
+	dir, cleanup := newDir(t)
+	defer cleanup()
+
+	assert.NoError(t, SelfTest(dir))
+}
+
+// The given directory is left as it was, even if the test fails.
+func TestSelfTest_DirUnchanged(t *testing.T) {
+	dir, cleanup := newDir(t)
+	defer cleanup()
+
+	SelfTest(dir)
+
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, files)
+}
@@ -0,0 +1,18 @@
+package dqlite
+
+import (
+	"fmt"
+
+	"github.com/canonical/go-dqlite/internal/bindings"
+)
+
+// Version returns the versions of the SQLite, dqlite and raft libraries that
+// this package is linked against, e.g. "3.40.0", "1.16.0" and "0.17.1".
+func Version() (sqlite, dqlite, raft string) {
+	return bindings.SQLiteVersion(), formatVersion(bindings.DqliteVersion()), formatVersion(bindings.RaftVersion())
+}
+
+// Format a version number encoded as MAJOR*10000 + MINOR*100 + RELEASE.
+func formatVersion(n int) string {
+	return fmt.Sprintf("%d.%d.%d", n/10000, n/100%100, n%100)
+}
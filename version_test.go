@@ -0,0 +1,12 @@
+package dqlite
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatVersion(t *testing.T) {
+	assert.Equal(t, "1.16.0", formatVersion(11600))
+	assert.Equal(t, "0.17.1", formatVersion(1701))
+}